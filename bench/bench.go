@@ -0,0 +1,244 @@
+// Package bench runs the shared performance test phases against a Driver
+// and collects the timings, so every library is measured the same way.
+package bench
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// Phase names, in the order Run executes them.
+const (
+	PhaseReset            = "Reset"
+	PhaseSeed             = "Seed"
+	PhaseRead             = "Read Count"
+	PhaseUpdate           = "Update"
+	PhaseDelete           = "Delete"
+	PhaseCreate           = "Create"
+	PhaseUpsert           = "Upsert"
+	PhaseSavepoint        = "Savepoint"
+	PhaseContention       = "Contention"
+	PhaseSchema           = "Schema Insert"
+	PhaseJSONBInsert      = "JSONB Insert"
+	PhaseJSONBUpdate      = "JSONB Update"
+	PhaseJSONBQuery       = "JSONB Query"
+	PhaseTypesInsert      = "Types Insert"
+	PhaseTypesRead        = "Types Read"
+	PhaseBytea            = "Bytea"
+	PhaseLargeObject      = "Large Object"
+	PhaseRelationsSeed    = "Relations Seed"
+	PhaseRelationsNaive   = "Relations N+1"
+	PhaseRelationsPreload = "Relations Preload"
+	PhaseRelationsJoin    = "Relations Join"
+	PhaseFinalRead        = "Final Read"
+)
+
+// User is a row to be inserted into the users table.
+// Each driver maps it onto its own model.
+type User struct {
+	// ID is set, in the text form of a uuid, when cfg.KeyStrategy makes
+	// the ids on the client; it is empty when the database assigns them.
+	ID        string
+	Name      string
+	Email     string
+	CreatedAt time.Time
+}
+
+// MaxParams is the largest number of bind parameters PostgreSQL accepts in
+// one statement.
+const MaxParams = 65535
+
+// UserParams returns the bind parameters of one users row in a multi-row
+// INSERT: name, email and created_at, and id when the client made it.
+func UserParams(users []User) int {
+	if len(users) > 0 && users[0].ID != "" {
+		return 4
+	}
+	return 3
+}
+
+// ParamChunks splits n rows of params bind parameters each into the
+// [from, to) ranges of the statements that stay within MaxParams. Drivers
+// whose multi-row INSERT sends a whole batch as one statement use it, as a
+// large -batch-size would exceed the limit.
+func ParamChunks(n, params int) [][2]int {
+	size := max(MaxParams/params, 1)
+	chunks := make([][2]int, 0, n/size+1)
+	for from := 0; from < n; from += size {
+		chunks = append(chunks, [2]int{from, min(from+size, n)})
+	}
+	return chunks
+}
+
+// Driver is implemented by every library under test.
+// Update and Delete select their target ids themselves, so the id lookup
+// is part of the measured time, as it is in a real application.
+type Driver interface {
+	// Name returns the label used in summaries, e.g. "GORM". Run reads it
+	// after Open, so it may include settings taken from the config.
+	Name() string
+	// Open connects to the database. Drivers read their own settings,
+	// such as pool sizes, from cfg.
+	Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error
+	Close() error
+	// Reset empties the users table and restarts its id sequence.
+	Reset(ctx context.Context) error
+	// Insert adds one batch of users.
+	Insert(ctx context.Context, users []User) error
+	Count(ctx context.Context) (int, error)
+	// Update renames up to n users and returns how many were targeted.
+	Update(ctx context.Context, n int) (int, error)
+	// Delete removes up to n users, skipping the first 1000 rows,
+	// and returns how many were targeted.
+	Delete(ctx context.Context, n int) (int, error)
+}
+
+// StatsReporter is implemented by drivers that expose cumulative counters,
+// such as connection pool statistics. Run records how much each counter
+// grew during every phase.
+type StatsReporter interface {
+	Stats() map[string]float64
+}
+
+// Upserter is implemented by drivers that support the Upsert phase. Drivers
+// without it skip the phase.
+type Upserter interface {
+	// Upsert inserts one batch of users, renaming the existing user instead
+	// when the email is already taken (INSERT ... ON CONFLICT (email)).
+	// It reports how many rows were inserted and how many were updated,
+	// as told by RETURNING (xmax = 0).
+	Upsert(ctx context.Context, users []User) (inserted, updated int, err error)
+}
+
+// Savepointer is implemented by drivers that support the Savepoint phase.
+type Savepointer interface {
+	// InsertNested inserts users in one transaction, each in a nested
+	// transaction (a SAVEPOINT) of its own, and rolls back the nested
+	// transaction of every user whose rollback flag is set.
+	InsertNested(ctx context.Context, users []User, rollback []bool) error
+}
+
+// Contender is implemented by drivers that support the Contention phase.
+type Contender interface {
+	// Contend runs one transaction with opts that renames the users with
+	// the given ids, one UPDATE per id in the given order, and returns the
+	// driver's error unwrapped enough for errors.As to find its SQLSTATE.
+	// It is called from several goroutines at once.
+	Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error
+}
+
+// SchemaExecer runs DDL for the phases that change the tables they use.
+type SchemaExecer interface {
+	// ExecSchema runs the given DDL statements in order.
+	ExecSchema(ctx context.Context, stmts []string) error
+}
+
+// RowInserter is implemented by drivers that support the Schema Insert
+// phase, which writes to a table described by a Schema instead of users.
+type RowInserter interface {
+	SchemaExecer
+	// InsertRows inserts one batch of rows into s.Table. Each row holds one
+	// value per column of s.Columns, in order.
+	InsertRows(ctx context.Context, s *Schema, rows [][]any) error
+}
+
+// Profiler is implemented by drivers that support the JSONB phases, which
+// use a profile jsonb column on users with a GIN index.
+type Profiler interface {
+	SchemaExecer
+	// InsertProfiles inserts one batch of users, each with its profile.
+	InsertProfiles(ctx context.Context, users []User, profiles []Profile) error
+	// SetProfileTheme sets preferences.theme to theme with jsonb_set on
+	// every user whose profile contains filter (@>), and returns how many
+	// users were updated.
+	SetProfileTheme(ctx context.Context, filter map[string]any, theme string) (int, error)
+	// FindProfiles returns the decoded profiles of the users whose profile
+	// contains filter (@>).
+	FindProfiles(ctx context.Context, filter map[string]any) ([]Profile, error)
+}
+
+// TypeMapper is implemented by drivers that support the Types phases,
+// which round-trip TypeRow values through the type_samples table.
+type TypeMapper interface {
+	SchemaExecer
+	// InsertTypeRows inserts one batch with TypeRowsInsertSQL, or with
+	// the same multi-row INSERT built by an ORM from its model.
+	InsertTypeRows(ctx context.Context, rows []TypeRow) error
+	// ReadTypeRows returns every row read with TypeRowsSelectSQL.
+	ReadTypeRows(ctx context.Context) ([]TypeRow, error)
+}
+
+// ByteaStore is implemented by drivers that support the Bytea phase.
+type ByteaStore interface {
+	SchemaExecer
+	// WriteBytea inserts data into the blobs table and returns the id of
+	// the new row.
+	WriteBytea(ctx context.Context, data []byte) (int64, error)
+	// ReadBytea returns the data of the blobs row with the id.
+	ReadBytea(ctx context.Context, id int64) ([]byte, error)
+}
+
+// LargeObjectStore is implemented by drivers that support the Large Object
+// phase.
+type LargeObjectStore interface {
+	// WriteLargeObject stores data as a new large object and returns its oid.
+	WriteLargeObject(ctx context.Context, data []byte) (uint32, error)
+	// ReadLargeObject returns the whole content of the large object.
+	ReadLargeObject(ctx context.Context, oid uint32) ([]byte, error)
+	UnlinkLargeObject(ctx context.Context, oid uint32) error
+}
+
+// RelationLoader is implemented by drivers that support the Relations
+// phases. Every method loads the first n users by id with their posts and
+// the comments of those posts, all in id order, and returns how many
+// queries it sent.
+type RelationLoader interface {
+	SchemaExecer
+	// LoadNaive queries the users, then the posts of every user and the
+	// comments of every post one at a time: 1 + n + n*posts queries, the
+	// N+1 pattern of lazy loading.
+	LoadNaive(ctx context.Context, n int) ([]UserPosts, int, error)
+	// LoadPreload queries the users, then the posts of all of them and the
+	// comments of all those posts with one query per table, as eager
+	// loading such as GORM's Preload does.
+	LoadPreload(ctx context.Context, n int) ([]UserPosts, int, error)
+	// LoadJoined loads them with as few queries as the library allows:
+	// a JOIN that aggregates posts and comments with json_agg, or GORM's
+	// Joins.
+	LoadJoined(ctx context.Context, n int) ([]UserPosts, int, error)
+}
+
+var drivers = map[string]func() Driver{}
+
+// Register makes a driver available under the given name.
+// It is called from the init function of each driver package.
+func Register(name string, newDriver func() Driver) {
+	if _, dup := drivers[name]; dup {
+		panic("bench: Register called twice for driver " + name)
+	}
+	drivers[name] = newDriver
+}
+
+// New returns a fresh instance of the named driver.
+func New(name string) (Driver, error) {
+	newDriver, ok := drivers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown driver %q (available: %v)", name, Drivers())
+	}
+	return newDriver(), nil
+}
+
+// Drivers returns the names of the registered drivers, sorted.
+func Drivers() []string {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
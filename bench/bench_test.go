@@ -0,0 +1,202 @@
+package bench_test
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"testing"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	_ "go-postgresql/driver/bundriver"
+	_ "go-postgresql/driver/entdriver"
+	_ "go-postgresql/driver/gormdriver"
+	_ "go-postgresql/driver/pgxdriver"
+	_ "go-postgresql/driver/pqdriver"
+	_ "go-postgresql/driver/sqlcdriver"
+	_ "go-postgresql/driver/sqlxdriver"
+	_ "go-postgresql/driver/squirreldriver"
+)
+
+// cfg can be changed with the usual flags, e.g.
+// go test -bench=. ./bench -args -batch-size=1000
+var cfg = config.GetConfig()
+
+func init() {
+	config.RegisterFlags(flag.CommandLine, cfg)
+}
+
+func BenchmarkSeed(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseSeed, []string{bench.PhaseReset}, false)
+}
+
+func BenchmarkReadCount(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseRead, []string{bench.PhaseReset, bench.PhaseSeed}, true)
+}
+
+func BenchmarkUpdate(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseUpdate, []string{bench.PhaseReset, bench.PhaseSeed}, true)
+}
+
+func BenchmarkDelete(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseDelete, []string{bench.PhaseReset, bench.PhaseSeed}, false)
+}
+
+func BenchmarkCreate(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseCreate, []string{bench.PhaseReset, bench.PhaseSeed}, false)
+}
+
+func BenchmarkUpsert(b *testing.B) {
+	benchmarkPhase(b, bench.PhaseUpsert, []string{bench.PhaseReset, bench.PhaseSeed, bench.PhaseCreate}, false)
+}
+
+// benchmarkPhase runs the named phase for every registered driver. The setup
+// phases run untimed, either once or, when the phase consumes or conflicts
+// with its own data, before every iteration.
+func benchmarkPhase(b *testing.B, name string, setup []string, setupOnce bool) {
+	dsn, ok := config.LookupDSN()
+	if !ok {
+		b.Skip("DATABASE_URL is not set")
+	}
+	phase, _ := bench.LookupPhase(name)
+	if err := bench.PrepareUsers(context.Background(), dsn, cfg); err != nil {
+		b.Fatal(err)
+	}
+
+	for _, driverName := range bench.Drivers() {
+		b.Run(fmt.Sprintf("%s/batch=%d", driverName, cfg.BatchSize), func(b *testing.B) {
+			ctx := context.Background()
+			d, err := bench.New(driverName)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := d.Open(ctx, dsn, cfg); err != nil {
+				b.Fatalf("failed to connect to database: %v", err)
+			}
+			defer d.Close()
+
+			runSetup := func() {
+				for _, s := range setup {
+					p, _ := bench.LookupPhase(s)
+					if _, err := p.Run(ctx, d, cfg, io.Discard); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+
+			if setupOnce {
+				runSetup()
+			}
+			rows := 0
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if !setupOnce {
+					b.StopTimer()
+					runSetup()
+					b.StartTimer()
+				}
+				pr, err := phase.Run(ctx, d, cfg, io.Discard)
+				if errors.Is(err, errors.ErrUnsupported) {
+					b.Skip(err)
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+				rows += pr.Count
+			}
+			b.ReportMetric(float64(rows)/b.Elapsed().Seconds(), "rows/s")
+		})
+	}
+}
+
+// TestUpsertTxScopePhase upserts several batches inside the single
+// transaction of -tx-scope=phase, where every batch of a driver must leave
+// the connection ready for the next one.
+func TestUpsertTxScopePhase(t *testing.T) {
+	dsn, ok := config.LookupDSN()
+	if !ok {
+		t.Skip("DATABASE_URL is not set")
+	}
+	c := *cfg
+	c.TxScope = config.TxScopePhase
+	c.InitialUsersCount = 100
+	c.NewUsersCount = 30
+	c.BatchSize = 10
+	c.UpsertCount = 35 // BatchSizeを超える件数で複数バッチにする
+	c.UpsertConflictPercent = 50
+	if err := bench.PrepareUsers(context.Background(), dsn, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, driverName := range bench.Drivers() {
+		t.Run(driverName, func(t *testing.T) {
+			ctx := context.Background()
+			d, err := bench.New(driverName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := d.(bench.Transactor); !ok {
+				t.Skipf("%s does not support -tx-scope", driverName)
+			}
+			if err := d.Open(ctx, dsn, &c); err != nil {
+				t.Fatalf("failed to connect to database: %v", err)
+			}
+			defer d.Close()
+
+			for _, name := range []string{bench.PhaseReset, bench.PhaseSeed, bench.PhaseCreate, bench.PhaseUpsert} {
+				p, _ := bench.LookupPhase(name)
+				pr, err := p.Run(ctx, d, &c, io.Discard)
+				if errors.Is(err, errors.ErrUnsupported) {
+					t.Skip(err)
+				}
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if name == bench.PhaseUpsert && len(pr.Batches) != 4 {
+					t.Errorf("Upsert ran %d batches, want 4", len(pr.Batches))
+				}
+			}
+		})
+	}
+}
+
+// TestInsertParamLimit seeds one batch of client-side uuid keys whose
+// multi-row INSERT would need more bind parameters than PostgreSQL accepts
+// in one statement.
+func TestInsertParamLimit(t *testing.T) {
+	dsn, ok := config.LookupDSN()
+	if !ok {
+		t.Skip("DATABASE_URL is not set")
+	}
+	c := *cfg
+	c.KeyStrategy = config.KeyUUIDv7
+	c.InitialUsersCount = 20000
+	c.BatchSize = 20000 // 20000行×4列で65535を超える
+	if err := bench.PrepareUsers(context.Background(), dsn, &c); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, driverName := range bench.Drivers() {
+		t.Run(driverName, func(t *testing.T) {
+			ctx := context.Background()
+			d, err := bench.New(driverName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := d.Open(ctx, dsn, &c); err != nil {
+				t.Fatalf("failed to connect to database: %v", err)
+			}
+			defer d.Close()
+
+			for _, name := range []string{bench.PhaseReset, bench.PhaseSeed} {
+				p, _ := bench.LookupPhase(name)
+				if _, err := p.Run(ctx, d, &c, io.Discard); err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/metrics"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// blobStatements recreate the blobs table of the Bytea phase, and
+// dropBlobStatements remove it again so that its rows do not stay on disk.
+var (
+	blobStatements = []string{
+		"DROP TABLE IF EXISTS blobs",
+		"CREATE TABLE blobs (id BIGSERIAL PRIMARY KEY, data BYTEA NOT NULL)",
+	}
+	dropBlobStatements = []string{
+		"DROP TABLE IF EXISTS blobs",
+	}
+)
+
+// blobPayload returns a buffer of cfg.BlobSize bytes. stampBlob marks it
+// with the blob number before every write, so a read that returns the
+// wrong blob is caught without generating every payload separately.
+func blobPayload(cfg *config.DatabaseConfig) []byte {
+	data := make([]byte, cfg.BlobSize)
+	for i := range data {
+		data[i] = byte(i*131 + i>>8)
+	}
+	return data
+}
+
+func stampBlob(data []byte, i int) {
+	var stamp [8]byte
+	binary.BigEndian.PutUint64(stamp[:], uint64(i))
+	copy(data, stamp[:])
+}
+
+// blobs checks that the Blob phases are enabled.
+func blobs(cfg *config.DatabaseConfig) error {
+	if cfg.BlobSize <= 0 || cfg.BlobCount <= 0 {
+		return fmt.Errorf("disabled, enable with -blob-size: %w", errors.ErrUnsupported)
+	}
+	return nil
+}
+
+// memSampler tracks the client heap while a phase runs. runtime/metrics
+// is read every millisecond, which is cheap enough not to skew the timing
+// of multi-megabyte transfers.
+type memSampler struct {
+	baseline uint64
+	peak     uint64
+	alloc    uint64
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var memSamples = []metrics.Sample{
+	{Name: "/memory/classes/heap/objects:bytes"},
+	{Name: "/gc/heap/allocs:bytes"},
+}
+
+func readMem() (heap, alloc uint64) {
+	s := make([]metrics.Sample, len(memSamples))
+	copy(s, memSamples)
+	metrics.Read(s)
+	return s[0].Value.Uint64(), s[1].Value.Uint64()
+}
+
+// startMemSampler collects garbage, so that the baseline only holds live
+// objects, and starts sampling.
+func startMemSampler() *memSampler {
+	runtime.GC()
+	m := &memSampler{stop: make(chan struct{}), done: make(chan struct{})}
+	m.baseline, m.alloc = readMem()
+	m.peak = m.baseline
+	go func() {
+		defer close(m.done)
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				if heap, _ := readMem(); heap > m.peak {
+					m.peak = heap
+				}
+			}
+		}
+	}()
+	return m
+}
+
+// Stop ends sampling and returns the peak heap growth over the baseline
+// and the bytes allocated meanwhile, both in MB.
+func (m *memSampler) Stop() (peakMB, allocMB float64) {
+	close(m.stop)
+	<-m.done
+	heap, alloc := readMem()
+	m.peak = max(m.peak, heap)
+	return mb(m.peak - m.baseline), mb(alloc - m.alloc)
+}
+
+func mb(n uint64) float64 { return float64(n) / (1 << 20) }
+
+// throughput returns n blobs of size bytes per d in MB/s.
+func throughput(n, size int, d time.Duration) float64 {
+	return mb(uint64(n)*uint64(size)) / d.Seconds()
+}
+
+// transferBlobs writes cfg.BlobCount blobs, then reads and checks them,
+// and returns the timings of both directions with the memory statistics.
+// write returns a key for read; cleanup runs after the timing stops.
+func transferBlobs[K any](cfg *config.DatabaseConfig, w io.Writer, label string,
+	write func(data []byte) (K, error), read func(key K) ([]byte, error)) (PhaseResult, []K, error) {
+	data := blobPayload(cfg)
+	keys := make([]K, 0, cfg.BlobCount)
+
+	mem := startMemSampler()
+	writeStart := time.Now()
+	for i := 0; i < cfg.BlobCount; i++ {
+		stampBlob(data, i)
+		key, err := write(data)
+		if err != nil {
+			mem.Stop()
+			return PhaseResult{}, keys, fmt.Errorf("failed to write %s %d: %w", label, i+1, err)
+		}
+		keys = append(keys, key)
+	}
+	writeDuration := time.Since(writeStart)
+
+	readStart := time.Now()
+	for i, key := range keys {
+		got, err := read(key)
+		if err != nil {
+			mem.Stop()
+			return PhaseResult{}, keys, fmt.Errorf("failed to read %s %d: %w", label, i+1, err)
+		}
+		stampBlob(data, i)
+		if !bytes.Equal(got, data) {
+			mem.Stop()
+			return PhaseResult{}, keys, fmt.Errorf("%s %d read back %d bytes that differ from the %d written", label, i+1, len(got), len(data))
+		}
+	}
+	readDuration := time.Since(readStart)
+	peakMB, allocMB := mem.Stop()
+
+	phase := PhaseResult{
+		Count:    cfg.BlobCount,
+		Duration: writeDuration + readDuration,
+		Stats: map[string]float64{
+			"Write(MB/s)":  throughput(cfg.BlobCount, cfg.BlobSize, writeDuration),
+			"Read(MB/s)":   throughput(cfg.BlobCount, cfg.BlobSize, readDuration),
+			"PeakHeap(MB)": peakMB,
+			"Alloc(MB)":    allocMB,
+		},
+	}
+	fmt.Fprintf(w, "Wrote %d %ss in %v (%.1f MB/s), read them back in %v (%.1f MB/s), peak heap +%.1f MB\n",
+		cfg.BlobCount, label, writeDuration, phase.Stats["Write(MB/s)"], readDuration, phase.Stats["Read(MB/s)"], peakMB)
+	return phase, keys, nil
+}
+
+// --- Bytea: Write and read blobs stored in a bytea column ---
+func runBytea(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if err := blobs(cfg); err != nil {
+		return PhaseResult{}, err
+	}
+	bs, ok := d.(ByteaStore)
+	if !ok {
+		return PhaseResult{}, fmt.Errorf("%s does not implement the Bytea phase: %w", d.Name(), errors.ErrUnsupported)
+	}
+
+	fmt.Fprintf(w, "\n=== Writing and reading %d bytea blobs of %d bytes ===\n", cfg.BlobCount, cfg.BlobSize)
+	if err := bs.ExecSchema(ctx, blobStatements); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to create table blobs: %w", err)
+	}
+	phase, _, err := transferBlobs(cfg, w, "blob",
+		func(data []byte) (int64, error) { return bs.WriteBytea(ctx, data) },
+		func(id int64) ([]byte, error) { return bs.ReadBytea(ctx, id) })
+
+	// 失敗した場合も書き込み済みの行が残らないようテーブルを削除する
+	if derr := bs.ExecSchema(ctx, dropBlobStatements); derr != nil && err == nil {
+		err = fmt.Errorf("failed to drop table blobs: %w", derr)
+	}
+	return phase, err
+}
+
+// --- Large Object: Write and read blobs through the large-object API ---
+func runLargeObject(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if err := blobs(cfg); err != nil {
+		return PhaseResult{}, err
+	}
+	lo, ok := d.(LargeObjectStore)
+	if !ok {
+		return PhaseResult{}, fmt.Errorf("%s does not implement the Large Object phase: %w", d.Name(), errors.ErrUnsupported)
+	}
+
+	fmt.Fprintf(w, "\n=== Writing and reading %d large objects of %d bytes ===\n", cfg.BlobCount, cfg.BlobSize)
+	phase, oids, err := transferBlobs(cfg, w, "large object",
+		func(data []byte) (uint32, error) { return lo.WriteLargeObject(ctx, data) },
+		func(oid uint32) ([]byte, error) { return lo.ReadLargeObject(ctx, oid) })
+
+	// 失敗した場合も作成済みのラージオブジェクトは削除する
+	for _, oid := range oids {
+		if uerr := lo.UnlinkLargeObject(ctx, oid); uerr != nil && err == nil {
+			err = fmt.Errorf("failed to unlink large object %d: %w", oid, uerr)
+		}
+	}
+	return phase, err
+}
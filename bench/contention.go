@@ -0,0 +1,160 @@
+package bench
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// SQLSTATE codes the contention workload retries.
+const (
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+)
+
+// --- Contention: Concurrent SERIALIZABLE updates of overlapping rows ---
+func runContention(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if cfg.ContentionClients <= 0 {
+		return PhaseResult{}, fmt.Errorf("disabled, enable with -contention-clients: %w", errors.ErrUnsupported)
+	}
+	if UUIDKeys(cfg) {
+		return PhaseResult{}, fmt.Errorf("the hot rows are ids 1-%d, which -key-strategy=%s does not have: %w", cfg.ContentionHotRows, cfg.KeyStrategy, errors.ErrUnsupported)
+	}
+	c, ok := d.(Contender)
+	if !ok {
+		// 単一の*pgx.Connを使うドライバーは同時クライアントを扱えない
+		return PhaseResult{}, fmt.Errorf("%s does not implement Contend, as one connection cannot serve concurrent clients: %w", d.Name(), errors.ErrUnsupported)
+	}
+
+	fmt.Fprintf(w, "\n=== Running %d clients x %d serializable transactions over ids 1-%d ===\n",
+		cfg.ContentionClients, cfg.ContentionTransactions, cfg.ContentionHotRows)
+
+	var (
+		mu    sync.Mutex
+		total contentionStats
+		wg    sync.WaitGroup
+		errs  = make([]error, cfg.ContentionClients)
+	)
+	contentionStart := time.Now()
+	for client := 0; client < cfg.ContentionClients; client++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			stats, err := runContentionClient(ctx, c, cfg, client)
+			errs[client] = err
+			mu.Lock()
+			total.add(stats)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	contentionDuration := time.Since(contentionStart)
+	if err := errors.Join(errs...); err != nil {
+		return PhaseResult{}, fmt.Errorf("contention workload: %w", err)
+	}
+
+	tps := float64(total.committed) / contentionDuration.Seconds()
+	fmt.Fprintf(w, "Committed %d transactions in %v (%.1f tx/s), %d retries (%d serialization failures, %d deadlocks), %d aborts\n",
+		total.committed, contentionDuration, tps, total.retries, total.serializationFailures, total.deadlocks, total.aborts)
+	return PhaseResult{
+		Count:    total.committed,
+		Duration: contentionDuration,
+		Stats: map[string]float64{
+			"Committed":             float64(total.committed),
+			"TPS":                   tps,
+			"Retries":               float64(total.retries),
+			"SerializationFailures": float64(total.serializationFailures),
+			"Deadlocks":             float64(total.deadlocks),
+			"Aborts":                float64(total.aborts),
+		},
+	}, nil
+}
+
+// contentionStats counts the outcomes of contention transactions.
+type contentionStats struct {
+	committed             int
+	retries               int
+	serializationFailures int
+	deadlocks             int
+	aborts                int // 最大試行回数に達して断念した数
+}
+
+func (s *contentionStats) add(o contentionStats) {
+	s.committed += o.committed
+	s.retries += o.retries
+	s.serializationFailures += o.serializationFailures
+	s.deadlocks += o.deadlocks
+	s.aborts += o.aborts
+}
+
+// runContentionClient runs one client's transactions. Each one renames
+// ContentionRows random ids out of 1..ContentionHotRows, in random order so
+// that clients also deadlock, and is retried with retryBackoff on
+// serialization failures and deadlocks. Any other error stops the client.
+func runContentionClient(ctx context.Context, c Contender, cfg *config.DatabaseConfig, client int) (contentionStats, error) {
+	var stats contentionStats
+	rng := rand.New(rand.NewPCG(uint64(client), 1)) // クライアントごとに再現可能な乱数
+	opts := &sql.TxOptions{Isolation: sql.LevelSerializable}
+
+	for i := 0; i < cfg.ContentionTransactions; i++ {
+		ids := make([]int, cfg.ContentionRows)
+		for j := range ids {
+			ids[j] = 1 + rng.IntN(cfg.ContentionHotRows)
+		}
+
+		for attempt := 1; ; attempt++ {
+			err := c.Contend(ctx, opts, ids)
+			if err == nil {
+				stats.committed++
+				break
+			}
+			switch sqlState(err) {
+			case sqlStateSerializationFailure:
+				stats.serializationFailures++
+			case sqlStateDeadlockDetected:
+				stats.deadlocks++
+			default:
+				return stats, fmt.Errorf("client %d: %w", client, err)
+			}
+			if attempt >= cfg.RetryMaxAttempts {
+				stats.aborts++
+				break
+			}
+			stats.retries++
+			time.Sleep(retryBackoff(cfg, attempt, rng))
+		}
+	}
+	return stats, nil
+}
+
+// retryBackoff returns the wait after the given failed attempt: RetryBackoff
+// doubled per attempt, capped at RetryMaxBackoff, with the upper half
+// jittered so that clients that failed together do not retry together.
+func retryBackoff(cfg *config.DatabaseConfig, attempt int, rng *rand.Rand) time.Duration {
+	backoff := cfg.RetryBackoff
+	for i := 1; i < attempt && backoff < cfg.RetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, cfg.RetryMaxBackoff)
+	if backoff <= 0 {
+		return 0
+	}
+	return backoff/2 + time.Duration(rng.Int64N(int64(backoff/2)+1))
+}
+
+// sqlState returns the SQLSTATE code of a server error. pgx (pgconn.PgError)
+// and lib/pq (pq.Error) both expose it through a SQLState method.
+func sqlState(err error) string {
+	var pgErr interface{ SQLState() string }
+	if errors.As(err, &pgErr) {
+		return pgErr.SQLState()
+	}
+	return ""
+}
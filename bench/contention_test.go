@@ -0,0 +1,40 @@
+package bench
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"go-postgresql/config"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	ms := time.Millisecond
+	tests := []struct {
+		name         string
+		backoff, max time.Duration
+		attempt      int
+		want         time.Duration // ジッター前の待機時間（結果はwant/2〜want）
+	}{
+		{name: "first retry", backoff: 10 * ms, max: 100 * ms, attempt: 1, want: 10 * ms},
+		{name: "doubled", backoff: 10 * ms, max: 100 * ms, attempt: 2, want: 20 * ms},
+		{name: "doubled twice", backoff: 10 * ms, max: 100 * ms, attempt: 3, want: 40 * ms},
+		{name: "capped", backoff: 10 * ms, max: 100 * ms, attempt: 5, want: 100 * ms},
+		{name: "capped long after", backoff: 10 * ms, max: 100 * ms, attempt: 100, want: 100 * ms},
+		{name: "backoff above max", backoff: time.Second, max: 100 * ms, attempt: 1, want: 100 * ms},
+		{name: "disabled", backoff: 0, max: 100 * ms, attempt: 3, want: 0},
+		{name: "zero max", backoff: 10 * ms, max: 0, attempt: 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.DatabaseConfig{RetryBackoff: tt.backoff, RetryMaxBackoff: tt.max}
+			rng := rand.New(rand.NewPCG(1, 1))
+			for range 100 {
+				got := retryBackoff(cfg, tt.attempt, rng)
+				if got < tt.want/2 || got > tt.want {
+					t.Fatalf("retryBackoff(attempt %d) = %v, want %v to %v", tt.attempt, got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
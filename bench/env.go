@@ -0,0 +1,40 @@
+package bench
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Env describes the machine and server a result was measured on.
+type Env struct {
+	GoVersion     string    `json:"go_version"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	NumCPU        int       `json:"num_cpu"`
+	Hostname      string    `json:"hostname"`
+	ServerVersion string    `json:"server_version,omitempty"`
+	StartedAt     time.Time `json:"started_at"`
+}
+
+// CollectEnv gathers the environment metadata. The server version is left
+// empty if it cannot be queried; it is informational only.
+func CollectEnv(ctx context.Context, dsn string) Env {
+	env := Env{
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		NumCPU:    runtime.NumCPU(),
+		StartedAt: time.Now(),
+	}
+	env.Hostname, _ = os.Hostname()
+
+	if conn, err := pgx.Connect(ctx, dsn); err == nil {
+		_ = conn.QueryRow(ctx, "SHOW server_version").Scan(&env.ServerVersion)
+		conn.Close(ctx)
+	}
+	return env
+}
@@ -0,0 +1,407 @@
+package bench
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// palette assigns one colour per driver, in column order.
+var palette = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#edc948", "#b07aa1", "#9c755f"}
+
+type htmlChart struct {
+	Title string
+	SVG   template.HTML
+}
+
+type htmlReport struct {
+	Generated    string
+	Table        template.HTML
+	PhaseCharts  []htmlChart
+	BatchCharts  []htmlChart
+	ScaleCharts  []htmlChart
+	Environments []*Result
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"ms": millis,
+}).Parse(`<!DOCTYPE html>
+<html lang="ja">
+<head>
+<meta charset="utf-8">
+<title>PostgreSQL Performance Benchmark</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.charts { display: flex; flex-wrap: wrap; gap: 1.5em; }
+.chart h3 { font-size: 1em; margin: 0.5em 0; }
+svg text { font-size: 11px; fill: #333; }
+</style>
+</head>
+<body>
+<h1>PostgreSQL Performance Benchmark</h1>
+<p>Generated {{.Generated}}. Values are medians in milliseconds.</p>
+{{.Table}}
+
+<h2>Phase timings</h2>
+<div class="charts">
+{{range .PhaseCharts}}<div class="chart"><h3>{{.Title}}</h3>{{.SVG}}</div>
+{{end}}</div>
+
+{{if .BatchCharts}}<h2>Batch latency distribution</h2>
+<p>Each dot is one insert batch; the box spans the 25th to 75th percentile and the bar marks the median.</p>
+<div class="charts">
+{{range .BatchCharts}}<div class="chart"><h3>{{.Title}}</h3>{{.SVG}}</div>
+{{end}}</div>
+{{end}}
+{{if .ScaleCharts}}<h2>Scaling</h2>
+<div class="charts">
+{{range .ScaleCharts}}<div class="chart"><h3>{{.Title}}</h3>{{.SVG}}</div>
+{{end}}</div>
+{{end}}
+<h2>Environment</h2>
+<table>
+<tr><th>Driver</th><th>Started</th><th>Host</th><th>Go</th><th>OS/Arch</th><th>CPUs</th><th>PostgreSQL</th><th>Config</th><th>Total (ms)</th></tr>
+{{range .Environments}}<tr><td>{{.Driver}}</td><td>{{.Env.StartedAt.Format "2006-01-02 15:04:05"}}</td><td>{{.Env.Hostname}}</td><td>{{.Env.GoVersion}}</td><td>{{.Env.OS}}/{{.Env.Arch}}</td><td>{{.Env.NumCPU}}</td><td>{{.Env.ServerVersion}}</td><td>{{printf "%+v" .Config}}</td><td>{{ms .Total}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// WriteHTML renders a self-contained HTML report with inline SVG charts.
+// The phase and batch charts use the runs sharing the configuration of the
+// first result; the scaling charts use every run.
+func WriteHTML(w io.Writer, results []*Result) error {
+	if len(results) == 0 {
+		return fmt.Errorf("no results")
+	}
+
+	var baseline []*Result
+	for _, r := range results {
+		if r.Config == results[0].Config {
+			baseline = append(baseline, r)
+		}
+	}
+	summaries := Summarize(baseline)
+
+	var table strings.Builder
+	table.WriteString("<table>\n<tr><th>操作 (件数)</th>")
+	for _, s := range summaries {
+		fmt.Fprintf(&table, "<th>%s (ms)</th>", html.EscapeString(s.Driver))
+	}
+	table.WriteString("</tr>\n")
+	phases := summaryPhaseNames(summaries)
+	for _, name := range phases {
+		fmt.Fprintf(&table, "<tr><td>%s</td>", html.EscapeString(phaseLabel(baseline[0], name)))
+		for _, s := range summaries {
+			if sp := s.Phase(name); sp != nil {
+				fmt.Fprintf(&table, "<td>%s</td>", millis(sp.Median))
+			} else {
+				table.WriteString("<td>-</td>")
+			}
+		}
+		table.WriteString("</tr>\n")
+	}
+	table.WriteString("<tr><th>Total Time</th>")
+	for _, s := range summaries {
+		fmt.Fprintf(&table, "<th>%s</th>", millis(s.Total.Median))
+	}
+	table.WriteString("</tr>\n</table>")
+
+	report := htmlReport{
+		Generated:    time.Now().Format("2006-01-02 15:04:05"),
+		Table:        template.HTML(table.String()),
+		Environments: results,
+	}
+
+	drivers := make([]string, len(summaries))
+	for i, s := range summaries {
+		drivers[i] = s.Driver
+	}
+
+	for _, name := range phases {
+		values := make([]float64, len(summaries))
+		for i, s := range summaries {
+			if sp := s.Phase(name); sp != nil {
+				values[i] = toMillis(sp.Median)
+			}
+		}
+		report.PhaseCharts = append(report.PhaseCharts, htmlChart{
+			Title: phaseLabel(baseline[0], name),
+			SVG:   barChart(drivers, values),
+		})
+	}
+
+	for _, phase := range []string{PhaseSeed, PhaseCreate} {
+		samples := make([][]float64, len(drivers))
+		found := false
+		for _, r := range baseline {
+			i := indexOf(drivers, r.Driver)
+			if rp := r.Phase(phase); rp != nil {
+				for _, b := range rp.Batches {
+					samples[i] = append(samples[i], toMillis(b.Duration))
+					found = true
+				}
+			}
+		}
+		if found {
+			report.BatchCharts = append(report.BatchCharts, htmlChart{
+				Title: fmt.Sprintf("%s batches (ms)", phase),
+				SVG:   boxPlot(drivers, samples),
+			})
+		}
+	}
+
+	for _, f := range config.Fields {
+		report.ScaleCharts = append(report.ScaleCharts, scalingCharts(results, f)...)
+	}
+
+	return htmlTemplate.Execute(w, report)
+}
+
+// scalingCharts draws the total, seed and create medians against one
+// configuration field, with one line per driver. Only the runs whose other
+// fields match the first result are used, so that a multi-dimensional sweep
+// yields one clean slice per field. It returns nothing when the field does
+// not vary.
+func scalingCharts(results []*Result, field config.Field) []htmlChart {
+	base := results[0].Config
+	*field.Ptr(&base) = 0
+	var slice []*Result
+	for _, r := range results {
+		c := r.Config
+		*field.Ptr(&c) = 0
+		if c == base {
+			slice = append(slice, r)
+		}
+	}
+
+	var xs []int
+	var drivers []string
+	for _, r := range slice {
+		if v := *field.Ptr(&r.Config); !containsInt(xs, v) {
+			xs = append(xs, v)
+		}
+		if indexOf(drivers, r.Driver) < 0 {
+			drivers = append(drivers, r.Driver)
+		}
+	}
+	if len(xs) < 2 {
+		return nil
+	}
+	sort.Ints(xs)
+
+	var charts []htmlChart
+	for _, metric := range []string{"Total Time", PhaseSeed, PhaseCreate} {
+		series := make([][]float64, len(drivers))
+		for i, driver := range drivers {
+			series[i] = make([]float64, len(xs))
+			for j, x := range xs {
+				series[i][j] = math.NaN()
+				var subset []*Result
+				for _, r := range slice {
+					if r.Driver == driver && *field.Ptr(&r.Config) == x {
+						subset = append(subset, r)
+					}
+				}
+				if len(subset) == 0 {
+					continue
+				}
+				s := Summarize(subset)[0]
+				if metric == "Total Time" {
+					series[i][j] = toMillis(s.Total.Median)
+				} else if sp := s.Phase(metric); sp != nil {
+					series[i][j] = toMillis(sp.Median)
+				}
+			}
+		}
+		charts = append(charts, htmlChart{
+			Title: fmt.Sprintf("%s (ms) by %s", metric, field.Name),
+			SVG:   lineChart(xs, drivers, series),
+		})
+	}
+	return charts
+}
+
+func toMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func containsInt(list []int, v int) bool {
+	for _, x := range list {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// Chart geometry shared by the SVG helpers.
+const (
+	chartWidth  = 460
+	labelWidth  = 110
+	rowHeight   = 26
+	chartMargin = 10
+)
+
+// barChart draws one horizontal bar per driver.
+func barChart(labels []string, values []float64) template.HTML {
+	max := 0.0
+	for _, v := range values {
+		max = math.Max(max, v)
+	}
+	plot := float64(chartWidth - labelWidth - 60)
+	height := len(labels)*rowHeight + 2*chartMargin
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, chartWidth, height)
+	for i, label := range labels {
+		y := chartMargin + i*rowHeight
+		width := 0.0
+		if max > 0 {
+			width = values[i] / max * plot
+		}
+		fmt.Fprintf(&b, `<text x="%d" y="%d" text-anchor="end">%s</text>`, labelWidth-6, y+16, html.EscapeString(label))
+		fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%d" fill="%s"/>`, labelWidth, y+4, width, rowHeight-8, palette[i%len(palette)])
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%.1f</text>`, float64(labelWidth)+width+4, y+16, values[i])
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// boxPlot draws the distribution of samples for each driver on a shared
+// horizontal axis.
+func boxPlot(labels []string, samples [][]float64) template.HTML {
+	max := 0.0
+	for _, s := range samples {
+		for _, v := range s {
+			max = math.Max(max, v)
+		}
+	}
+	plot := float64(chartWidth - labelWidth - 20)
+	scale := func(v float64) float64 {
+		if max == 0 {
+			return float64(labelWidth)
+		}
+		return float64(labelWidth) + v/max*plot
+	}
+	height := len(labels)*rowHeight + 2*chartMargin + 16
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, chartWidth, height)
+	for i, label := range labels {
+		y := float64(chartMargin + i*rowHeight)
+		mid := y + rowHeight/2
+		color := palette[i%len(palette)]
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`, labelWidth-6, mid+4, html.EscapeString(label))
+		if len(samples[i]) == 0 {
+			continue
+		}
+		s := append([]float64(nil), samples[i]...)
+		sort.Float64s(s)
+		q1, median, q3 := quantile(s, 0.25), quantile(s, 0.5), quantile(s, 0.75)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s"/>`, scale(s[0]), mid, scale(s[len(s)-1]), mid, color)
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" fill="%s" fill-opacity="0.3" stroke="%s"/>`,
+			scale(q1), y+5, scale(q3)-scale(q1), rowHeight-10, color, color)
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="2"/>`, scale(median), y+3, scale(median), y+rowHeight-3, color)
+		for _, v := range s {
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="2" fill="%s" fill-opacity="0.6"/>`, scale(v), mid, color)
+		}
+	}
+	axisY := chartMargin + len(labels)*rowHeight + 12
+	fmt.Fprintf(&b, `<text x="%d" y="%d">0</text>`, labelWidth, axisY)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="end">%.1f ms</text>`, scale(max), axisY, max)
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// lineChart draws one line per driver over evenly spaced x values, so that
+// widely spread parameters such as batch sizes stay readable. NaN values
+// leave a gap.
+func lineChart(xs []int, labels []string, series [][]float64) template.HTML {
+	const height = 240
+	const left, right, top, bottom = 60, 120, 10, 30
+	max := 0.0
+	for _, s := range series {
+		for _, v := range s {
+			if !math.IsNaN(v) {
+				max = math.Max(max, v)
+			}
+		}
+	}
+	if max == 0 {
+		max = 1
+	}
+	plotW := float64(chartWidth - left - right)
+	plotH := float64(height - top - bottom)
+	px := func(j int) float64 {
+		if len(xs) == 1 {
+			return left + plotW/2
+		}
+		return left + float64(j)/float64(len(xs)-1)*plotW
+	}
+	py := func(v float64) float64 { return top + plotH - v/max*plotH }
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d">`, chartWidth, height)
+	for k := 0; k <= 4; k++ {
+		v := max * float64(k) / 4
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`, left, py(v), left+plotW, py(v))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%.1f</text>`, left-4, py(v)+4, v)
+	}
+	for j, x := range xs {
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%d</text>`, px(j), height-bottom+16, x)
+	}
+	for i, s := range series {
+		color := palette[i%len(palette)]
+		var points []string
+		flush := func() {
+			if len(points) > 1 {
+				fmt.Fprintf(&b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="2"/>`, strings.Join(points, " "), color)
+			}
+			points = nil
+		}
+		for j, v := range s {
+			if math.IsNaN(v) {
+				flush()
+				continue
+			}
+			points = append(points, fmt.Sprintf("%.1f,%.1f", px(j), py(v)))
+			fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`, px(j), py(v), color)
+		}
+		flush()
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%d" width="10" height="10" fill="%s"/>`, left+plotW+10, top+i*16, color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s</text>`, left+plotW+24, top+i*16+9, html.EscapeString(labels[i]))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// quantile returns the q-quantile of sorted using linear interpolation.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (sorted[hi]-sorted[lo])*(pos-float64(lo))
+}
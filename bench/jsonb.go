@@ -0,0 +1,270 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// Profile is the document stored in the users.profile jsonb column by the
+// JSONB phases. Drivers encode and decode it with their library's natural
+// JSON mapping, so that cost is part of the measured time. The mapping
+// calls MarshalJSON and UnmarshalJSON, so the phases also report the time
+// spent in encoding/json on its own.
+type Profile struct {
+	Age         int         `json:"age"`
+	Country     string      `json:"country"`
+	Tags        []string    `json:"tags"`
+	Preferences Preferences `json:"preferences"`
+}
+
+// Preferences is the nested object of a Profile; the JSONB Update phase
+// changes its theme with jsonb_set.
+type Preferences struct {
+	Theme         string `json:"theme"`
+	Language      string `json:"language"`
+	Notifications bool   `json:"notifications"`
+}
+
+// profileCountries are assigned round robin, so every country holds the
+// same share of the profiles.
+var profileCountries = []string{"JP", "US", "DE", "FR", "BR"}
+
+// profileStatements add the profile column and its GIN index, and
+// dropProfileStatements remove them again, see dropProfile.
+var (
+	profileStatements = []string{
+		"ALTER TABLE users ADD COLUMN IF NOT EXISTS profile JSONB",
+		"CREATE INDEX IF NOT EXISTS users_profile_idx ON users USING GIN (profile)",
+	}
+	dropProfileStatements = []string{
+		"ALTER TABLE users DROP COLUMN IF EXISTS profile",
+	}
+)
+
+// jsonEncode and jsonDecode accumulate the nanoseconds spent in
+// MarshalJSON and UnmarshalJSON.
+var jsonEncode, jsonDecode atomic.Int64
+
+// MarshalJSON is json.Marshal, timed for the Encode(µs) figure of the
+// JSONB phases. Drivers use it for the profile documents and filters,
+// either directly or as the marshal function of their library's codec.
+func MarshalJSON(v any) ([]byte, error) {
+	start := time.Now()
+	data, err := json.Marshal(v)
+	jsonEncode.Add(int64(time.Since(start)))
+	return data, err
+}
+
+// UnmarshalJSON is json.Unmarshal, timed for the Decode(µs) figure of the
+// JSONB phases.
+func UnmarshalJSON(data []byte, v any) error {
+	start := time.Now()
+	err := json.Unmarshal(data, v)
+	jsonDecode.Add(int64(time.Since(start)))
+	return err
+}
+
+// jsonCodecTime is the time spent in MarshalJSON and UnmarshalJSON so
+// far. A phase takes one when it starts and reports the difference.
+type jsonCodecTime struct {
+	encode, decode time.Duration
+}
+
+func jsonCodecNow() jsonCodecTime {
+	return jsonCodecTime{time.Duration(jsonEncode.Load()), time.Duration(jsonDecode.Load())}
+}
+
+// report adds the encode and decode time since t to stats in µs and
+// prints them.
+func (t jsonCodecTime) report(stats map[string]float64, w io.Writer) {
+	now := jsonCodecNow()
+	encode, decode := now.encode-t.encode, now.decode-t.decode
+	stats["Encode(µs)"] = float64(encode.Microseconds())
+	stats["Decode(µs)"] = float64(decode.Microseconds())
+	fmt.Fprintf(w, "JSON encode %v, decode %v\n", encode, decode)
+}
+
+// newProfile returns the profile of the i-th (0-based) JSONB user.
+func newProfile(i int) Profile {
+	return Profile{
+		Age:     20 + i%50,
+		Country: profileCountries[i%len(profileCountries)],
+		Tags:    []string{fmt.Sprintf("tag%d", i%10), fmt.Sprintf("tag%d", 10+i%7)},
+		Preferences: Preferences{
+			Theme:         "light",
+			Language:      []string{"ja", "en", "de"}[i%3],
+			Notifications: i%2 == 0,
+		},
+	}
+}
+
+// profilesIn returns how many of the first n profiles have the country.
+func profilesIn(n int, country string) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if profileCountries[i%len(profileCountries)] == country {
+			count++
+		}
+	}
+	return count
+}
+
+// profiler returns d as a Profiler, or an error wrapping
+// errors.ErrUnsupported when the JSONB phases are disabled or d lacks them.
+func profiler(d Driver, cfg *config.DatabaseConfig) (Profiler, error) {
+	if cfg.JsonbCount <= 0 {
+		return nil, fmt.Errorf("disabled, enable with -jsonb-count: %w", errors.ErrUnsupported)
+	}
+	p, ok := d.(Profiler)
+	if !ok {
+		return nil, fmt.Errorf("%s does not implement the JSONB phases: %w", d.Name(), errors.ErrUnsupported)
+	}
+	return p, nil
+}
+
+// dropProfile removes the profile column and its index at the end of Run,
+// whether the JSONB phases succeeded or not, so that later runs measure the
+// plain users table.
+func dropProfile(ctx context.Context, d Driver, cfg *config.DatabaseConfig) error {
+	p, err := profiler(d, cfg)
+	if err != nil {
+		return nil // JSONBフェーズは実行されていない
+	}
+	if err := p.ExecSchema(ctx, dropProfileStatements); err != nil {
+		return fmt.Errorf("failed to drop profile column: %w", err)
+	}
+	return nil
+}
+
+// --- JSONB Insert: Add users with a structured profile document ---
+func runJSONBInsert(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	p, err := profiler(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+
+	fmt.Fprintf(w, "\n=== Inserting %d users with jsonb profiles ===\n", cfg.JsonbCount)
+	if err := p.ExecSchema(ctx, profileStatements); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to add profile column: %w", err)
+	}
+
+	codec := jsonCodecNow()
+	next := 0 // 次のバッチの先頭ユーザーの番号
+	newUser := func(j int) User {
+		return User{
+			Name:      fmt.Sprintf("Jsonb_User_%06d", j+1),
+			Email:     fmt.Sprintf("jsonbuser%06d@example.com", j+1),
+			CreatedAt: time.Now(),
+		}
+	}
+	insert, err := runBatches(ctx, d, cfg, cfg.JsonbCount, newUser,
+		func(ctx context.Context, users []User) error {
+			profiles := make([]Profile, len(users))
+			for k := range users {
+				profiles[k] = newProfile(next + k)
+			}
+			next += len(users)
+			return p.InsertProfiles(ctx, users, profiles)
+		},
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "JSONB batch %d-%d inserted in %v\n", from, to, dur)
+		})
+	if err != nil {
+		return insert, fmt.Errorf("failed to insert profiles: %w", err)
+	}
+
+	perRow := float64(insert.Duration.Microseconds()) / float64(cfg.JsonbCount)
+	fmt.Fprintf(w, "Inserted %d profiles in %v (%.1fµs per row)\n", cfg.JsonbCount, insert.Duration, perRow)
+	insert.Stats = map[string]float64{"PerRow(µs)": perRow}
+	codec.report(insert.Stats, w)
+	return insert, nil
+}
+
+// --- JSONB Update: Change a nested key of every profile with jsonb_set ---
+func runJSONBUpdate(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	p, err := profiler(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+
+	fmt.Fprintf(w, "\n=== Setting preferences.theme of %d profiles, one country at a time ===\n", cfg.JsonbCount)
+	codec := jsonCodecNow()
+	updateStart := time.Now()
+	updated := 0
+	err = withTx(ctx, d, cfg, func(ctx context.Context) error {
+		for _, country := range profileCountries {
+			err := withTx(ctx, d, cfg, func(ctx context.Context) error {
+				n, err := p.SetProfileTheme(ctx, map[string]any{"country": country}, "dark")
+				updated += n
+				return err
+			}, config.TxScopeBatch)
+			if err != nil {
+				return fmt.Errorf("country %s: %w", country, err)
+			}
+		}
+		return nil
+	}, config.TxScopePhase)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to update profiles: %w", err)
+	}
+	updateDuration := time.Since(updateStart)
+	if updated != cfg.JsonbCount {
+		return PhaseResult{}, fmt.Errorf("jsonb_set updated %d profiles, want %d", updated, cfg.JsonbCount)
+	}
+	fmt.Fprintf(w, "Updated %d profiles in %v\n", updated, updateDuration)
+	phase := PhaseResult{Count: updated, Duration: updateDuration, Stats: map[string]float64{}}
+	codec.report(phase.Stats, w)
+	return phase, nil
+}
+
+// --- JSONB Query: Find and decode profiles with @> containment ---
+func runJSONBQuery(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	p, err := profiler(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+
+	fmt.Fprintf(w, "\n=== Running %d @> containment queries ===\n", cfg.JsonbQueryCount)
+	codec := jsonCodecNow()
+	queryStart := time.Now()
+	rows := 0
+	for k := 0; k < cfg.JsonbQueryCount; k++ {
+		country := profileCountries[k%len(profileCountries)]
+		filter := map[string]any{
+			"country":     country,
+			"preferences": map[string]any{"theme": "dark"},
+		}
+		profiles, err := p.FindProfiles(ctx, filter)
+		if err != nil {
+			return PhaseResult{}, fmt.Errorf("failed to query profiles: %w", err)
+		}
+		if want := profilesIn(cfg.JsonbCount, country); len(profiles) != want {
+			return PhaseResult{}, fmt.Errorf("query for %s returned %d profiles, want %d", country, len(profiles), want)
+		}
+		rows += len(profiles)
+	}
+	queryDuration := time.Since(queryStart)
+
+	perRow := 0.0
+	if rows > 0 {
+		perRow = float64(queryDuration.Microseconds()) / float64(rows)
+	}
+	fmt.Fprintf(w, "Decoded %d profiles from %d queries in %v (%.1fµs per row)\n", rows, cfg.JsonbQueryCount, queryDuration, perRow)
+	phase := PhaseResult{
+		Count:    rows,
+		Duration: queryDuration,
+		Stats: map[string]float64{
+			"Queries":    float64(cfg.JsonbQueryCount),
+			"PerRow(µs)": perRow,
+		},
+	}
+	codec.report(phase.Stats, w)
+	return phase, nil
+}
@@ -0,0 +1,197 @@
+package bench
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// keyColumns maps every config.KeyStrategies value to the definition of
+// users.id. The other columns are the same as in init/init.sql.
+var keyColumns = map[string]string{
+	config.KeySerial:        "id SERIAL PRIMARY KEY",
+	config.KeyIdentity:      "id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+	config.KeyUUIDv4:        "id UUID PRIMARY KEY",
+	config.KeyUUIDv7:        "id UUID PRIMARY KEY",
+	config.KeyGenRandomUUID: "id UUID PRIMARY KEY DEFAULT gen_random_uuid()",
+}
+
+// createUsersSQL creates the users table with the given id column.
+func createUsersSQL(id string) string {
+	return fmt.Sprintf(`CREATE TABLE users (
+		%s,
+		name VARCHAR(100) NOT NULL,
+		email VARCHAR(100) UNIQUE NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+	)`, id)
+}
+
+// keyColumnOf returns the keyColumns definition of an existing users.id,
+// given its data_type, is_identity and column_default in
+// information_schema.columns, or "" when it matches none of them.
+func keyColumnOf(dataType, isIdentity, columnDefault string) string {
+	switch {
+	case dataType == "integer" && strings.HasPrefix(columnDefault, "nextval("):
+		return keyColumns[config.KeySerial]
+	case dataType == "bigint" && isIdentity == "YES":
+		return keyColumns[config.KeyIdentity]
+	case dataType == "uuid" && columnDefault == "gen_random_uuid()":
+		return keyColumns[config.KeyGenRandomUUID]
+	case dataType == "uuid" && columnDefault == "":
+		return keyColumns[config.KeyUUIDv4]
+	}
+	return ""
+}
+
+// PrepareUsers makes sure that the users table has the id column of
+// cfg.KeyStrategy. A table that already has it is left alone, rows
+// included, so the default serial strategy runs on the table of
+// init/init.sql. Otherwise the table is dropped without CASCADE, which
+// fails while views or foreign keys depend on it, and created again.
+// PrepareUsers connects on its own, like CollectEnv, so that every driver
+// runs on the same table whether or not it can run DDL.
+func PrepareUsers(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	want, ok := keyColumns[cfg.KeyStrategy]
+	if !ok {
+		return fmt.Errorf("unknown key strategy %q (available: %v)", cfg.KeyStrategy, config.KeyStrategies)
+	}
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(ctx)
+
+	var dataType, isIdentity, columnDefault string
+	err = conn.QueryRow(ctx, `SELECT data_type, is_identity, COALESCE(column_default, '')
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = 'users' AND column_name = 'id'`).
+		Scan(&dataType, &isIdentity, &columnDefault)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		// テーブルがないので作成する
+	case err != nil:
+		return fmt.Errorf("failed to inspect table users: %w", err)
+	case keyColumnOf(dataType, isIdentity, columnDefault) == want:
+		return nil
+	default:
+		if _, err := conn.Exec(ctx, "DROP TABLE users"); err != nil {
+			return fmt.Errorf("failed to drop table users to change its key to %s, drop the objects that depend on it first: %w", cfg.KeyStrategy, err)
+		}
+	}
+	if _, err := conn.Exec(ctx, createUsersSQL(want)); err != nil {
+		return fmt.Errorf("failed to create table users: %w", err)
+	}
+	return nil
+}
+
+// UUIDKeys reports whether users.id is a uuid under cfg.KeyStrategy.
+// Drivers then pass and scan ids in their text form, as strings.
+func UUIDKeys(cfg *config.DatabaseConfig) bool {
+	switch cfg.KeyStrategy {
+	case config.KeyUUIDv4, config.KeyUUIDv7, config.KeyGenRandomUUID:
+		return true
+	}
+	return false
+}
+
+// newKey returns the generator of User.ID for the key strategies whose ids
+// are made by the client, or nil when the database assigns them.
+func newKey(cfg *config.DatabaseConfig) func() string {
+	switch cfg.KeyStrategy {
+	case config.KeyUUIDv4:
+		return uuidV4
+	case config.KeyUUIDv7:
+		return uuidV7
+	}
+	return nil
+}
+
+// uuidV4 returns a random (version 4) uuid, read from crypto/rand as
+// github.com/google/uuid does.
+func uuidV4() string {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return FormatUUID(u)
+}
+
+// uuidV7 returns a time-ordered (version 7) uuid: the Unix time in
+// milliseconds followed by random bits, so that consecutive ids land next
+// to each other in the primary key index.
+func uuidV7() string {
+	var u [16]byte
+	rand.Read(u[6:])
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(time.Now().UnixMilli()))
+	copy(u[:6], ms[2:])
+	u[6] = u[6]&0x0f | 0x70 // version 7
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	return FormatUUID(u)
+}
+
+// FormatUUID formats a uuid as 8-4-4-4-12 hex digits, its text form in
+// PostgreSQL.
+func FormatUUID(u [16]byte) string {
+	var buf [36]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// relationSizes returns the pg_relation_size of users and of its two
+// indexes in MB, keyed by the Stats names of the Seed phase.
+func relationSizes(ctx context.Context, dsn string) (map[string]float64, error) {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close(ctx)
+
+	var table, pkey, email int64
+	err = conn.QueryRow(ctx, `SELECT pg_relation_size('users'), pg_relation_size('users_pkey'), pg_relation_size('users_email_key')`).
+		Scan(&table, &pkey, &email)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]float64{
+		"Table(MB)":    mb(uint64(table)),
+		"PKeyIdx(MB)":  mb(uint64(pkey)),
+		"EmailIdx(MB)": mb(uint64(email)),
+	}, nil
+}
+
+// reportSizes adds the relation sizes to the Seed phase and prints them.
+// They are informational, like Env, so a failure only prints a note.
+func reportSizes(ctx context.Context, dsn string, pr *PhaseResult, w io.Writer) {
+	sizes, err := relationSizes(ctx, dsn)
+	if err != nil {
+		fmt.Fprintf(w, "Could not read relation sizes: %v\n", err)
+		return
+	}
+	if pr.Stats == nil {
+		pr.Stats = map[string]float64{}
+	}
+	for k, v := range sizes {
+		pr.Stats[k] = v
+	}
+	fmt.Fprintf(w, "Relation sizes: users %.1f MB, users_pkey %.1f MB, users_email_key %.1f MB\n",
+		sizes["Table(MB)"], sizes["PKeyIdx(MB)"], sizes["EmailIdx(MB)"])
+}
@@ -0,0 +1,95 @@
+package bench
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// parseTestUUID decodes the text form of a uuid.
+func parseTestUUID(t *testing.T, s string) [16]byte {
+	t.Helper()
+	var u [16]byte
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != len(u) || len(s) != 36 {
+		t.Fatalf("invalid uuid %q", s)
+	}
+	copy(u[:], b)
+	return u
+}
+
+func TestUUIDV7(t *testing.T) {
+	before := time.Now().UnixMilli()
+	var ids []string
+	for range 5 {
+		ids = append(ids, uuidV7())
+		time.Sleep(2 * time.Millisecond)
+	}
+	after := time.Now().UnixMilli()
+
+	for i, id := range ids {
+		u := parseTestUUID(t, id)
+		if v := u[6] >> 4; v != 7 {
+			t.Errorf("%s has version %d, want 7", id, v)
+		}
+		if u[8]&0xc0 != 0x80 {
+			t.Errorf("%s does not have the RFC 9562 variant", id)
+		}
+		var ms [8]byte
+		copy(ms[2:], u[:6])
+		if ts := int64(binary.BigEndian.Uint64(ms[:])); ts < before || ts > after {
+			t.Errorf("%s has timestamp %d, want %d to %d", id, ts, before, after)
+		}
+		// 別のミリ秒に生成したIDはテキスト表現でも生成順に並ぶ
+		if i > 0 && ids[i-1] >= id {
+			t.Errorf("%s sorts before the earlier %s", id, ids[i-1])
+		}
+	}
+}
+
+func TestUUIDV4(t *testing.T) {
+	seen := map[string]bool{}
+	for range 100 {
+		id := uuidV4()
+		u := parseTestUUID(t, id)
+		if v := u[6] >> 4; v != 4 {
+			t.Errorf("%s has version %d, want 4", id, v)
+		}
+		if u[8]&0xc0 != 0x80 {
+			t.Errorf("%s does not have the RFC 9562 variant", id)
+		}
+		if seen[id] {
+			t.Errorf("%s generated twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestKeyColumnOf(t *testing.T) {
+	tests := []struct {
+		dataType, isIdentity, columnDefault string
+		want                                string
+	}{
+		{"integer", "NO", "nextval('users_id_seq'::regclass)", keyColumns[config.KeySerial]},
+		{"bigint", "YES", "", keyColumns[config.KeyIdentity]},
+		{"uuid", "NO", "", keyColumns[config.KeyUUIDv4]},
+		{"uuid", "NO", "gen_random_uuid()", keyColumns[config.KeyGenRandomUUID]},
+		{"integer", "NO", "", ""},
+		{"bigint", "NO", "nextval('users_id_seq'::regclass)", ""},
+		{"uuid", "NO", "uuid_generate_v4()", ""},
+		{"text", "NO", "", ""},
+	}
+	for _, tt := range tests {
+		if got := keyColumnOf(tt.dataType, tt.isIdentity, tt.columnDefault); got != tt.want {
+			t.Errorf("keyColumnOf(%q, %q, %q) = %q, want %q", tt.dataType, tt.isIdentity, tt.columnDefault, got, tt.want)
+		}
+	}
+	// uuidv4とuuidv7は同じ列定義なので、作り直さずに切り替えられる
+	if keyColumns[config.KeyUUIDv4] != keyColumns[config.KeyUUIDv7] {
+		t.Error("uuidv4 and uuidv7 have different key columns")
+	}
+}
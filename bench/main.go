@@ -0,0 +1,43 @@
+package bench
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"go-postgresql/config"
+)
+
+// Main is the entry point shared by the cmd/* binaries. It runs the named
+// driver once, prints its summary and, with -json, stores the result so
+// that cmd/bench can collect it. With -driver, a binary can run any other
+// driver registered by the packages it imports, such as a variant of its
+// default driver.
+func Main(name string) {
+	// Load configuration
+	cfg := config.GetConfig()
+
+	driverName := flag.String("driver", name, "driver to run, from: "+strings.Join(Drivers(), ","))
+	jsonPath := flag.String("json", "", "write the result as JSON to this file")
+	config.RegisterFlags(flag.CommandLine, cfg)
+	flag.Parse()
+
+	d, err := New(*driverName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	res, err := Run(context.Background(), d, config.DSN(), cfg, os.Stdout)
+	if err != nil {
+		log.Fatal(err)
+	}
+	PrintSummary(os.Stdout, res)
+
+	if *jsonPath != "" {
+		if err := WriteResults(*jsonPath, []*Result{res}); err != nil {
+			log.Fatalf("Failed to write result: %v", err)
+		}
+	}
+}
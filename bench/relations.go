@@ -0,0 +1,248 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// UserPosts is a user with its posts, as loaded by the Relations phases.
+type UserPosts struct {
+	Name  string
+	Email string
+	Posts []Post
+}
+
+// Post is one row of the posts table with its comments. The json tags
+// match the objects built by UserPostsJSONSQL.
+type Post struct {
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	Comments []Comment `json:"comments"`
+}
+
+// Comment is one row of the comments table.
+type Comment struct {
+	Body string `json:"body"`
+}
+
+// The queries of the Relations phases for the drivers that write SQL by
+// hand. Every list is in id order, so that all strategies load the same
+// users, posts and comments in the same order.
+const (
+	// RelationUsersSQL selects the first $1 users.
+	RelationUsersSQL = "SELECT id, name, email FROM users ORDER BY id LIMIT $1"
+	// PostsOfUserSQL and CommentsOfPostSQL are the per-row queries of
+	// LoadNaive.
+	PostsOfUserSQL    = "SELECT id, title, body FROM posts WHERE user_id = $1 ORDER BY id"
+	CommentsOfPostSQL = "SELECT body FROM comments WHERE post_id = $1 ORDER BY id"
+	// PostsOfUsersSQL and CommentsOfPostsSQL are the per-table queries of
+	// LoadPreload, taking the ids as an array.
+	PostsOfUsersSQL    = "SELECT id, user_id, title, body FROM posts WHERE user_id = ANY($1) ORDER BY id"
+	CommentsOfPostsSQL = "SELECT post_id, body FROM comments WHERE post_id = ANY($1) ORDER BY id"
+	// UserPostsJSONSQL is the single query of LoadJoined: the first $1
+	// users joined with their posts, which are aggregated with json_agg
+	// into one array per user, each post holding its comments.
+	UserPostsJSONSQL = `SELECT u.name, u.email,
+		COALESCE(json_agg(json_build_object(
+			'title', p.title,
+			'body', p.body,
+			'comments', (SELECT COALESCE(json_agg(json_build_object('body', c.body) ORDER BY c.id), '[]')
+				FROM comments c WHERE c.post_id = p.id)
+		) ORDER BY p.id) FILTER (WHERE p.id IS NOT NULL), '[]')
+	FROM (SELECT id, name, email FROM users ORDER BY id LIMIT $1) u
+	LEFT JOIN posts p ON p.user_id = u.id
+	GROUP BY u.id, u.name, u.email
+	ORDER BY u.id`
+)
+
+// userIDTypes maps every config.KeyStrategies value to the type of the
+// posts.user_id foreign key.
+var userIDTypes = map[string]string{
+	config.KeySerial:        "INTEGER",
+	config.KeyIdentity:      "BIGINT",
+	config.KeyUUIDv4:        "UUID",
+	config.KeyUUIDv7:        "UUID",
+	config.KeyGenRandomUUID: "UUID",
+}
+
+// relationStatements recreate posts and comments and seed them on the
+// server: cfg.PostsPerUser posts for every user and cfg.CommentsPerPost
+// comments for every post. The foreign key indexes are built after the
+// rows are in, as a bulk load would.
+func relationStatements(cfg *config.DatabaseConfig) []string {
+	return []string{
+		"DROP TABLE IF EXISTS comments, posts",
+		fmt.Sprintf(`CREATE TABLE posts (
+			id BIGSERIAL PRIMARY KEY,
+			user_id %s NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+			title VARCHAR(200) NOT NULL,
+			body TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`, userIDTypes[cfg.KeyStrategy]),
+		`CREATE TABLE comments (
+			id BIGSERIAL PRIMARY KEY,
+			post_id BIGINT NOT NULL REFERENCES posts (id) ON DELETE CASCADE,
+			body TEXT NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+		)`,
+		fmt.Sprintf(`INSERT INTO posts (user_id, title, body)
+			SELECT u.id, 'Post ' || g || ' by ' || u.name, repeat('Lorem ipsum dolor sit amet. ', 8)
+			FROM users u CROSS JOIN generate_series(1, %d) g
+			ORDER BY u.id, g`, cfg.PostsPerUser),
+		fmt.Sprintf(`INSERT INTO comments (post_id, body)
+			SELECT p.id, 'Comment ' || g || ' on post ' || p.id
+			FROM posts p CROSS JOIN generate_series(1, %d) g
+			ORDER BY p.id, g`, cfg.CommentsPerPost),
+		"CREATE INDEX posts_user_id_idx ON posts (user_id)",
+		"CREATE INDEX comments_post_id_idx ON comments (post_id)",
+		"ANALYZE users, posts, comments",
+	}
+}
+
+// dropRelationStatements remove posts and comments; see dropRelations.
+var dropRelationStatements = []string{
+	"DROP TABLE IF EXISTS comments, posts",
+}
+
+// relationLoader returns d as a RelationLoader, or an error wrapping
+// errors.ErrUnsupported when the Relations phases are disabled or d lacks
+// them.
+func relationLoader(d Driver, cfg *config.DatabaseConfig) (RelationLoader, error) {
+	if cfg.RelationsUsers <= 0 {
+		return nil, fmt.Errorf("disabled, enable with -relations-users: %w", errors.ErrUnsupported)
+	}
+	if cfg.PostsPerUser <= 0 {
+		return nil, fmt.Errorf("disabled, -posts-per-user must be at least 1: %w", errors.ErrUnsupported)
+	}
+	rl, ok := d.(RelationLoader)
+	if !ok {
+		return nil, fmt.Errorf("%s does not implement the Relations phases: %w", d.Name(), errors.ErrUnsupported)
+	}
+	return rl, nil
+}
+
+// --- Relations Seed: Create posts and comments for every user ---
+func runRelationsSeed(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	rl, err := relationLoader(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	userCount, err := d.Count(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	fmt.Fprintf(w, "\n=== Seeding %d posts per user and %d comments per post for %d users ===\n",
+		cfg.PostsPerUser, cfg.CommentsPerPost, userCount)
+	seedStart := time.Now()
+	if err := rl.ExecSchema(ctx, relationStatements(cfg)); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to seed posts and comments: %w", err)
+	}
+	seedDuration := time.Since(seedStart)
+
+	posts := userCount * cfg.PostsPerUser
+	comments := posts * max(cfg.CommentsPerPost, 0)
+	fmt.Fprintf(w, "Seeded %d posts and %d comments in %v\n", posts, comments, seedDuration)
+	return PhaseResult{
+		Count:    posts + comments,
+		Duration: seedDuration,
+		Stats:    map[string]float64{"Posts": float64(posts), "Comments": float64(comments)},
+	}, nil
+}
+
+// --- Relations N+1: Load users with their posts one query per row ---
+func runRelationsNaive(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	return loadRelations(ctx, d, cfg, w, "one query per user and post", RelationLoader.LoadNaive)
+}
+
+// --- Relations Preload: Load users with their posts one query per table ---
+func runRelationsPreload(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	return loadRelations(ctx, d, cfg, w, "one query per table", RelationLoader.LoadPreload)
+}
+
+// --- Relations Join: Load users with their posts in a single join ---
+func runRelationsJoin(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	return loadRelations(ctx, d, cfg, w, "a join", RelationLoader.LoadJoined)
+}
+
+// dropRelations removes posts and comments at the end of Run, whether the
+// Relations phases succeeded or not, so that they never outlive a run.
+func dropRelations(ctx context.Context, d Driver, cfg *config.DatabaseConfig) error {
+	rl, err := relationLoader(d, cfg)
+	if err != nil {
+		return nil // Relationsフェーズは実行されていない
+	}
+	if err := rl.ExecSchema(ctx, dropRelationStatements); err != nil {
+		return fmt.Errorf("failed to drop posts and comments: %w", err)
+	}
+	return nil
+}
+
+// loadRelations times one load strategy, checks that it returned every
+// post and comment of the first cfg.RelationsUsers users, and reports the
+// queries it needed.
+func loadRelations(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer, how string,
+	load func(rl RelationLoader, ctx context.Context, n int) ([]UserPosts, int, error)) (PhaseResult, error) {
+	rl, err := relationLoader(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+	userCount, err := d.Count(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	fmt.Fprintf(w, "\n=== Loading %d users with their posts and comments, %s ===\n", cfg.RelationsUsers, how)
+	loadStart := time.Now()
+	users, queries, err := load(rl, ctx, cfg.RelationsUsers)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to load users with posts: %w", err)
+	}
+	loadDuration := time.Since(loadStart)
+	if err := checkUserPosts(users, min(cfg.RelationsUsers, userCount), cfg); err != nil {
+		return PhaseResult{}, err
+	}
+
+	posts, comments := 0, 0
+	for _, u := range users {
+		posts += len(u.Posts)
+		for _, p := range u.Posts {
+			comments += len(p.Comments)
+		}
+	}
+	perUser := float64(loadDuration.Microseconds()) / float64(max(len(users), 1))
+	fmt.Fprintf(w, "Loaded %d users, %d posts and %d comments with %d queries in %v (%.1fµs per user)\n",
+		len(users), posts, comments, queries, loadDuration, perUser)
+	return PhaseResult{
+		Count:    len(users),
+		Duration: loadDuration,
+		Stats: map[string]float64{
+			"Queries":     float64(queries),
+			"PerUser(µs)": perUser,
+		},
+	}, nil
+}
+
+// checkUserPosts checks that want users were loaded, each with
+// cfg.PostsPerUser posts of cfg.CommentsPerPost comments.
+func checkUserPosts(users []UserPosts, want int, cfg *config.DatabaseConfig) error {
+	if len(users) != want {
+		return fmt.Errorf("loaded %d users, want %d", len(users), want)
+	}
+	for i, u := range users {
+		if len(u.Posts) != cfg.PostsPerUser {
+			return fmt.Errorf("user %d (%s) has %d posts, want %d", i+1, u.Email, len(u.Posts), cfg.PostsPerUser)
+		}
+		for j, p := range u.Posts {
+			if len(p.Comments) != max(cfg.CommentsPerPost, 0) {
+				return fmt.Errorf("post %d of user %d (%s) has %d comments, want %d", j+1, i+1, u.Email, len(p.Comments), cfg.CommentsPerPost)
+			}
+		}
+	}
+	return nil
+}
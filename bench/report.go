@@ -0,0 +1,241 @@
+package bench
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// Stats describes the spread of one timing over several runs.
+type Stats struct {
+	Median time.Duration
+	Min    time.Duration
+	Max    time.Duration
+}
+
+// Spread returns half the distance between the slowest and the fastest run.
+func (s Stats) Spread() time.Duration {
+	return (s.Max - s.Min) / 2
+}
+
+// PhaseStats aggregates one phase over several runs.
+type PhaseStats struct {
+	Name string
+	Stats
+}
+
+// Summary aggregates every run of one driver.
+type Summary struct {
+	Driver string
+	Config config.DatabaseConfig
+	Runs   int
+	Phases []PhaseStats
+	Total  Stats
+}
+
+// Summarize groups results by driver, keeping the order in which each driver
+// first appears, and computes the median of every phase.
+func Summarize(results []*Result) []Summary {
+	var order []string
+	byDriver := map[string][]*Result{}
+	for _, r := range results {
+		if _, ok := byDriver[r.Driver]; !ok {
+			order = append(order, r.Driver)
+		}
+		byDriver[r.Driver] = append(byDriver[r.Driver], r)
+	}
+
+	summaries := make([]Summary, 0, len(order))
+	for _, driver := range order {
+		runs := byDriver[driver]
+		s := Summary{Driver: driver, Config: runs[0].Config, Runs: len(runs)}
+		for _, p := range runs[0].Phases {
+			var durations []time.Duration
+			for _, r := range runs {
+				if rp := r.Phase(p.Name); rp != nil {
+					durations = append(durations, rp.Duration)
+				}
+			}
+			s.Phases = append(s.Phases, PhaseStats{Name: p.Name, Stats: newStats(durations)})
+		}
+		totals := make([]time.Duration, 0, len(runs))
+		for _, r := range runs {
+			totals = append(totals, r.Total)
+		}
+		s.Total = newStats(totals)
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+// Phase returns the stats of the named phase, or nil if it was not recorded.
+func (s *Summary) Phase(name string) *PhaseStats {
+	for i := range s.Phases {
+		if s.Phases[i].Name == name {
+			return &s.Phases[i]
+		}
+	}
+	return nil
+}
+
+// summaryPhaseNames returns the phases recorded by any of summaries, in the
+// order Run executes them.
+func summaryPhaseNames(summaries []Summary) []string {
+	var names []string
+	for _, s := range summaries {
+		for _, p := range s.Phases {
+			if indexOf(names, p.Name) < 0 {
+				names = append(names, p.Name)
+			}
+		}
+	}
+	sortPhaseNames(names)
+	return names
+}
+
+func newStats(durations []time.Duration) Stats {
+	if len(durations) == 0 {
+		return Stats{}
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+	return Stats{Median: median, Min: sorted[0], Max: sorted[len(sorted)-1]}
+}
+
+// WriteMarkdown renders the summaries as the Markdown table used in the
+// README: one row per phase, one column per driver, median milliseconds.
+// The bulk phases and the total are set in bold. With spread, every cell is
+// followed by "± half the min-max range".
+func WriteMarkdown(w io.Writer, summaries []Summary, spread bool) {
+	if len(summaries) == 0 {
+		return
+	}
+
+	header := []string{"操作 (件数)"}
+	for _, s := range summaries {
+		header = append(header, s.Driver+" (ms)")
+	}
+	rows := [][]string{header}
+
+	cell := func(st Stats, bold bool) string {
+		v := millis(st.Median)
+		if spread {
+			v += " ± " + millis(st.Spread())
+		}
+		if bold {
+			v = "**" + v + "**"
+		}
+		return v
+	}
+
+	first := summaries[0]
+	for _, name := range summaryPhaseNames(summaries) {
+		label, bold := markdownLabel(&first.Config, name)
+		row := []string{label}
+		for _, s := range summaries {
+			if sp := s.Phase(name); sp != nil {
+				row = append(row, cell(sp.Stats, bold))
+			} else {
+				row = append(row, "-")
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	total := []string{"**Total Time**"}
+	for _, s := range summaries {
+		total = append(total, cell(s.Total, true))
+	}
+	rows = append(rows, total)
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, c := range row {
+			if n := displayWidth(c); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+
+	writeRow := func(row []string) {
+		fmt.Fprint(w, "|")
+		for i, c := range row {
+			fmt.Fprintf(w, " %s%s |", c, strings.Repeat(" ", widths[i]-displayWidth(c)))
+		}
+		fmt.Fprintln(w)
+	}
+
+	writeRow(rows[0])
+	fmt.Fprint(w, "|")
+	for _, width := range widths {
+		fmt.Fprintf(w, " %s |", strings.Repeat("-", width))
+	}
+	fmt.Fprintln(w)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+}
+
+// displayWidth returns the number of terminal columns s occupies, counting
+// East Asian wide characters such as the Japanese header as two.
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case r >= 0x1100 && r <= 0x115F,
+			r >= 0x2E80 && r <= 0xA4CF,
+			r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF,
+			r >= 0xFE30 && r <= 0xFE4F,
+			r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// markdownLabel returns the row label of a phase and whether it is one of
+// the bulk phases, which the README highlights.
+func markdownLabel(cfg *config.DatabaseConfig, name string) (string, bool) {
+	var count int
+	switch name {
+	case PhaseSeed:
+		count = cfg.InitialUsersCount
+	case PhaseUpdate:
+		count = cfg.UpdateCount
+	case PhaseDelete:
+		count = cfg.DeleteCount
+	case PhaseCreate:
+		count = cfg.NewUsersCount
+	case PhaseUpsert:
+		count = cfg.UpsertCount
+	default:
+		return name, false
+	}
+	return fmt.Sprintf("**%s (%s)**", name, formatCount(count)), true
+}
+
+// formatCount formats n with thousands separators, e.g. 50000 -> "50,000".
+func formatCount(n int) string {
+	if n < 0 {
+		return "-" + formatCount(-n)
+	}
+	s := strconv.Itoa(n)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
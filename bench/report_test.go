@@ -0,0 +1,114 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-postgresql/config"
+)
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{50000, "50,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{-1000, "-1,000"},
+		{-999, "-999"},
+	}
+	for _, tt := range tests {
+		if got := formatCount(tt.n); got != tt.want {
+			t.Errorf("formatCount(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		s    string
+		want int
+	}{
+		{"", 0},
+		{"PGX (ms)", 8},
+		{"**1500.0**", 10},
+		{"操作", 4},
+		{"操作 (件数)", 11},
+		{"ｱ", 1},  // 半角カナは1列
+		{"Ａ", 2},  // 全角英字は2列
+		{"한글", 4}, // ハングルは2列
+		{"±", 1},
+	}
+	for _, tt := range tests {
+		if got := displayWidth(tt.s); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	ms := time.Millisecond
+	summaries := []Summary{
+		{
+			Driver: "PGX",
+			Config: config.DatabaseConfig{InitialUsersCount: 50000},
+			Phases: []PhaseStats{
+				{Name: PhaseSeed, Stats: Stats{Median: 1500 * ms, Min: 1400 * ms, Max: 1600 * ms}},
+				{Name: PhaseRead, Stats: Stats{Median: 2 * ms, Min: 2 * ms, Max: 2 * ms}},
+			},
+			Total: Stats{Median: 2000 * ms, Min: 1900 * ms, Max: 2100 * ms},
+		},
+		{
+			Driver: "GORM",
+			Config: config.DatabaseConfig{InitialUsersCount: 50000},
+			Phases: []PhaseStats{
+				{Name: PhaseSeed, Stats: Stats{Median: 3000 * ms, Min: 3000 * ms, Max: 3000 * ms}},
+			},
+			Total: Stats{Median: 3500 * ms, Min: 3500 * ms, Max: 3500 * ms},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		summaries []Summary
+		spread    bool
+		want      string
+	}{
+		{name: "no summaries", summaries: nil, want: ""},
+		{
+			name:      "medians",
+			summaries: summaries,
+			want: `| 操作 (件数)       | PGX (ms)   | GORM (ms)  |
+| ----------------- | ---------- | ---------- |
+| **Seed (50,000)** | **1500.0** | **3000.0** |
+| Read Count        | 2.0        | -          |
+| **Total Time**    | **2000.0** | **3500.0** |
+`,
+		},
+		{
+			name:      "spread",
+			summaries: summaries[:1],
+			spread:    true,
+			want: `| 操作 (件数)       | PGX (ms)           |
+| ----------------- | ------------------ |
+| **Seed (50,000)** | **1500.0 ± 100.0** |
+| Read Count        | 2.0 ± 0.0          |
+| **Total Time**    | **2000.0 ± 100.0** |
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			WriteMarkdown(&b, tt.summaries, tt.spread)
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteMarkdown wrote\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
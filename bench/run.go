@@ -0,0 +1,386 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// Result holds the timings of one complete run of a driver.
+type Result struct {
+	Driver string                `json:"driver"`
+	Config config.DatabaseConfig `json:"config"`
+	Env    Env                   `json:"env"`
+	Phases []PhaseResult         `json:"phases"`
+	Total  time.Duration         `json:"total_ns"`
+}
+
+// PhaseResult holds the timing of a single phase.
+// Count is the number of rows the phase worked on.
+type PhaseResult struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Duration time.Duration `json:"duration_ns"`
+	Batches  []BatchResult `json:"batches,omitempty"`
+	// Stats holds the growth of the driver's counters during the phase,
+	// see StatsReporter.
+	Stats map[string]float64 `json:"stats,omitempty"`
+}
+
+// BatchResult holds the timing of one insert batch covering rows From..To.
+type BatchResult struct {
+	From     int           `json:"from"`
+	To       int           `json:"to"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Phase returns the named phase, or nil if the run did not record it.
+func (r *Result) Phase(name string) *PhaseResult {
+	for i := range r.Phases {
+		if r.Phases[i].Name == name {
+			return &r.Phases[i]
+		}
+	}
+	return nil
+}
+
+// Phase is one step of the benchmark. Run measures the step and returns its
+// timing, writing progress to w.
+type Phase struct {
+	Name string
+	Run  func(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error)
+}
+
+// Phases lists every phase in the order Run executes them.
+// The testing.B benchmarks reuse the same functions. A phase that needs an
+// optional interface the driver lacks returns an error wrapping
+// errors.ErrUnsupported, and Run leaves it out of the result.
+var Phases = []Phase{
+	{PhaseReset, runReset},
+	{PhaseSeed, runSeed},
+	{PhaseRead, runRead},
+	{PhaseUpdate, runUpdate},
+	{PhaseDelete, runDelete},
+	{PhaseCreate, runCreate},
+	{PhaseUpsert, runUpsert},
+	{PhaseSavepoint, runSavepoint},
+	{PhaseContention, runContention},
+	{PhaseSchema, runSchema},
+	{PhaseJSONBInsert, runJSONBInsert},
+	{PhaseJSONBUpdate, runJSONBUpdate},
+	{PhaseJSONBQuery, runJSONBQuery},
+	{PhaseTypesInsert, runTypesInsert},
+	{PhaseTypesRead, runTypesRead},
+	{PhaseBytea, runBytea},
+	{PhaseLargeObject, runLargeObject},
+	{PhaseRelationsSeed, runRelationsSeed},
+	{PhaseRelationsNaive, runRelationsNaive},
+	{PhaseRelationsPreload, runRelationsPreload},
+	{PhaseRelationsJoin, runRelationsJoin},
+	{PhaseFinalRead, runFinalRead},
+}
+
+// LookupPhase returns the named phase.
+func LookupPhase(name string) (Phase, bool) {
+	for _, p := range Phases {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Phase{}, false
+}
+
+// Run prepares the users table for cfg.KeyStrategy, opens d and executes
+// every phase in order, writing progress to w. The total time includes
+// opening the connection. What the optional phases add to the database is
+// removed by teardown before returning, also when a phase fails.
+func Run(ctx context.Context, d Driver, dsn string, cfg *config.DatabaseConfig, w io.Writer) (res *Result, err error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	opts, err := TxOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := d.(Transactor); opts != nil && !ok {
+		return nil, fmt.Errorf("%s does not support -tx-scope", d.Name())
+	}
+
+	res = &Result{Config: *cfg, Env: CollectEnv(ctx, dsn)}
+	if err := PrepareUsers(ctx, dsn, cfg); err != nil {
+		return nil, err
+	}
+	totalStart := time.Now()
+
+	if err := d.Open(ctx, dsn, cfg); err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer d.Close()
+	// フェーズが途中で失敗しても追加した列やテーブルが残らないよう必ず削除する
+	defer func() {
+		if derr := teardown(ctx, d, cfg); derr != nil && err == nil {
+			res, err = nil, derr
+		}
+	}()
+	// 名前はOpenで受け取った設定を反映するため接続後に出力する
+	res.Driver = d.Name()
+	log.Printf("go-postgresql (%s version) starting up - Performance Test Mode", res.Driver)
+
+	log.Println("Database connection successful.")
+
+	reporter, _ := d.(StatsReporter)
+	for _, p := range Phases {
+		var before map[string]float64
+		if reporter != nil {
+			before = reporter.Stats()
+		}
+		pr, err := p.Run(ctx, d, cfg, w)
+		if errors.Is(err, errors.ErrUnsupported) {
+			fmt.Fprintf(w, "\n=== Skipping %s: %v ===\n", p.Name, err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pr.Name = p.Name
+		if p.Name == PhaseSeed {
+			reportSizes(ctx, dsn, &pr, w)
+		}
+		if reporter != nil {
+			if pr.Stats == nil {
+				pr.Stats = map[string]float64{}
+			}
+			for k, v := range reporter.Stats() {
+				pr.Stats[k] = v - before[k]
+			}
+		}
+		res.Phases = append(res.Phases, pr)
+	}
+
+	res.Total = time.Since(totalStart)
+	return res, nil
+}
+
+// teardown removes the profile column of the JSONB phases and the tables of
+// the Relations phases, so that they never outlive a run.
+func teardown(ctx context.Context, d Driver, cfg *config.DatabaseConfig) error {
+	return errors.Join(dropProfile(ctx, d, cfg), dropRelations(ctx, d, cfg))
+}
+
+// --- Reset database for idempotent run ---
+func runReset(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintln(w, "\n=== Resetting database for a clean run ===")
+	resetStart := time.Now()
+	if err := d.Reset(ctx); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to truncate users table: %w", err)
+	}
+	resetDuration := time.Since(resetStart)
+	fmt.Fprintf(w, "Table 'users' cleared in %v\n", resetDuration)
+	return PhaseResult{Duration: resetDuration}, nil
+}
+
+// --- Seed large amount of initial data ---
+func runSeed(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintf(w, "\n=== Seeding %d initial users ===\n", cfg.InitialUsersCount)
+	seed, err := insertBatches(ctx, d, cfg, cfg.InitialUsersCount, "User_%06d", "user%06d@example.com",
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "Batch %d-%d inserted in %v\n", from, to, dur)
+		})
+	if err != nil {
+		return seed, fmt.Errorf("failed to seed users: %w", err)
+	}
+	fmt.Fprintf(w, "Initial data seeding completed in %v\n", seed.Duration)
+	return seed, nil
+}
+
+// --- Read: Get user count ---
+func runRead(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintln(w, "\n=== Reading user count after seeding ===")
+	readStart := time.Now()
+	userCount, err := d.Count(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to count users: %w", err)
+	}
+	readDuration := time.Since(readStart)
+	fmt.Fprintf(w, "Found %d users in %v\n", userCount, readDuration)
+	return PhaseResult{Count: userCount, Duration: readDuration}, nil
+}
+
+// --- Update: Change multiple users' names ---
+func runUpdate(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintf(w, "\n=== Updating %d users ===\n", cfg.UpdateCount)
+	updateStart := time.Now()
+	var updated int
+	err := withTx(ctx, d, cfg, func(ctx context.Context) (err error) {
+		updated, err = d.Update(ctx, cfg.UpdateCount)
+		return err
+	}, config.TxScopeBatch, config.TxScopePhase)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to bulk update users: %w", err)
+	}
+	updateDuration := time.Since(updateStart)
+	fmt.Fprintf(w, "Updated %d users in %v\n", updated, updateDuration)
+	return PhaseResult{Count: updated, Duration: updateDuration}, nil
+}
+
+// --- Delete: Remove multiple users ---
+func runDelete(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintf(w, "\n=== Deleting %d users ===\n", cfg.DeleteCount)
+	deleteStart := time.Now()
+	var deleted int
+	err := withTx(ctx, d, cfg, func(ctx context.Context) (err error) {
+		deleted, err = d.Delete(ctx, cfg.DeleteCount)
+		return err
+	}, config.TxScopeBatch, config.TxScopePhase)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to bulk delete users: %w", err)
+	}
+	deleteDuration := time.Since(deleteStart)
+	fmt.Fprintf(w, "Deleted %d users in %v\n", deleted, deleteDuration)
+	return PhaseResult{Count: deleted, Duration: deleteDuration}, nil
+}
+
+// --- Create: Add new users ---
+func runCreate(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintf(w, "\n=== Creating %d new users ===\n", cfg.NewUsersCount)
+	create, err := insertBatches(ctx, d, cfg, cfg.NewUsersCount, "New_User_%06d", "newuser%06d@example.com",
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "New batch %d-%d created in %v\n", from, to, dur)
+		})
+	if err != nil {
+		return create, fmt.Errorf("failed to create users: %w", err)
+	}
+	fmt.Fprintf(w, "Created %d new users in %v\n", cfg.NewUsersCount, create.Duration)
+	return create, nil
+}
+
+// --- Upsert: Insert new users and rename existing ones on email conflicts ---
+func runUpsert(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if cfg.UpsertCount <= 0 {
+		return PhaseResult{}, fmt.Errorf("disabled, enable with -upsert-count: %w", errors.ErrUnsupported)
+	}
+	u, ok := d.(Upserter)
+	if !ok {
+		return PhaseResult{}, fmt.Errorf("%s does not implement Upsert: %w", d.Name(), errors.ErrUnsupported)
+	}
+
+	fmt.Fprintf(w, "\n=== Upserting %d users (%d%% existing emails) ===\n", cfg.UpsertCount, cfg.UpsertConflictPercent)
+	var inserted, updated int
+	upsert, err := runBatches(ctx, d, cfg, cfg.UpsertCount, func(i int) User { return upsertUser(cfg, i) },
+		func(ctx context.Context, users []User) error {
+			ins, upd, err := u.Upsert(ctx, users)
+			inserted += ins
+			updated += upd
+			return err
+		},
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "Upsert batch %d-%d completed in %v\n", from, to, dur)
+		})
+	if err != nil {
+		return upsert, fmt.Errorf("failed to upsert users: %w", err)
+	}
+	upsert.Stats = map[string]float64{"Inserted": float64(inserted), "Updated": float64(updated)}
+	fmt.Fprintf(w, "Upserted %d users (%d inserted, %d updated) in %v\n", cfg.UpsertCount, inserted, updated, upsert.Duration)
+	return upsert, nil
+}
+
+// upsertUser returns the i-th (0-based) user of the Upsert phase. Conflicts
+// are spread evenly: UpsertConflictPercent of the users reuse the email of
+// a user added by the Create phase, the rest have new emails. Conflicts are
+// capped at NewUsersCount so no email appears twice.
+func upsertUser(cfg *config.DatabaseConfig, i int) User {
+	pct := cfg.UpsertConflictPercent
+	conflicts := i * pct / 100 // 先行するユーザーのうち既存メールアドレスの数
+	if (i+1)*pct/100 > conflicts && conflicts < cfg.NewUsersCount {
+		return User{
+			Name:      fmt.Sprintf("Upserted_User_%06d", i+1),
+			Email:     fmt.Sprintf("newuser%06d@example.com", conflicts+1),
+			CreatedAt: time.Now(),
+		}
+	}
+	return User{
+		Name:      fmt.Sprintf("Upserted_User_%06d", i+1),
+		Email:     fmt.Sprintf("upsertuser%06d@example.com", i+1),
+		CreatedAt: time.Now(),
+	}
+}
+
+// --- Final Read: Get final user count ---
+func runFinalRead(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	fmt.Fprintln(w, "\n=== Final user count ===")
+	finalReadStart := time.Now()
+	userCount, err := d.Count(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to count final users: %w", err)
+	}
+	finalReadDuration := time.Since(finalReadStart)
+	fmt.Fprintf(w, "Final user count: %d (retrieved in %v)\n", userCount, finalReadDuration)
+	return PhaseResult{Count: userCount, Duration: finalReadDuration}, nil
+}
+
+// insertBatches inserts total users in batches of cfg.BatchSize, naming them
+// with the given formats, and reports each batch through done.
+func insertBatches(ctx context.Context, d Driver, cfg *config.DatabaseConfig, total int, nameFormat, emailFormat string, done func(from, to int, dur time.Duration)) (PhaseResult, error) {
+	newUser := func(j int) User {
+		return User{
+			Name:      fmt.Sprintf(nameFormat, j+1),
+			Email:     fmt.Sprintf(emailFormat, j+1),
+			CreatedAt: time.Now(),
+		}
+	}
+	return runBatches(ctx, d, cfg, total, newUser, d.Insert, done)
+}
+
+// runBatches passes total users, made by newUser, to exec in batches of
+// cfg.BatchSize and reports each batch through done. The users get their
+// ids here when cfg.KeyStrategy makes them on the client, so generating
+// them is part of the measured time. Each batch, or the whole phase, runs
+// in a transaction as cfg.TxScope requests.
+func runBatches(ctx context.Context, d Driver, cfg *config.DatabaseConfig, total int, newUser func(j int) User, exec func(ctx context.Context, users []User) error, done func(from, to int, dur time.Duration)) (PhaseResult, error) {
+	key := newKey(cfg)
+	return insertRows(ctx, d, cfg, total, cfg.BatchSize, func(ctx context.Context, from, to int) error {
+		users := make([]User, 0, to-from)
+		for j := from; j < to; j++ {
+			u := newUser(j)
+			if key != nil {
+				u.ID = key()
+			}
+			users = append(users, u)
+		}
+		return exec(ctx, users)
+	}, done)
+}
+
+// insertRows calls insert for the 0-based rows [from, to) of every batch of
+// batchSize rows out of total, and reports each batch through done with
+// 1-based row numbers. Each batch, or the whole phase, runs in a
+// transaction as cfg.TxScope requests.
+func insertRows(ctx context.Context, d Driver, cfg *config.DatabaseConfig, total, batchSize int, insert func(ctx context.Context, from, to int) error, done func(from, to int, dur time.Duration)) (PhaseResult, error) {
+	phase := PhaseResult{Count: total}
+	start := time.Now()
+	err := withTx(ctx, d, cfg, func(ctx context.Context) error {
+		for i := 0; i < total; i += batchSize {
+			batchStart := time.Now()
+			end := min(i+batchSize, total)
+
+			err := withTx(ctx, d, cfg, func(ctx context.Context) error {
+				return insert(ctx, i, end)
+			}, config.TxScopeBatch)
+			if err != nil {
+				return fmt.Errorf("batch %d-%d: %w", i+1, end, err)
+			}
+
+			batchDuration := time.Since(batchStart)
+			phase.Batches = append(phase.Batches, BatchResult{From: i + 1, To: end, Duration: batchDuration})
+			done(i+1, end, batchDuration)
+		}
+		return nil
+	}, config.TxScopePhase)
+	phase.Duration = time.Since(start)
+	return phase, err
+}
@@ -0,0 +1,86 @@
+package bench
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go-postgresql/config"
+)
+
+func TestUpsertUser(t *testing.T) {
+	tests := []struct {
+		name          string
+		upserts       int
+		percent       int
+		newUsers      int
+		wantConflicts int
+		wantMaxRun    int // 連続する衝突・非衝突の最大長（均等に分散しているか）
+	}{
+		{name: "no conflicts", upserts: 100, percent: 0, newUsers: 1000, wantConflicts: 0, wantMaxRun: 100},
+		{name: "all conflicts", upserts: 100, percent: 100, newUsers: 1000, wantConflicts: 100, wantMaxRun: 100},
+		{name: "half", upserts: 100, percent: 50, newUsers: 1000, wantConflicts: 50, wantMaxRun: 1},
+		{name: "a tenth", upserts: 1000, percent: 10, newUsers: 1000, wantConflicts: 100, wantMaxRun: 9},
+		{name: "capped at NewUsersCount", upserts: 100, percent: 50, newUsers: 10, wantConflicts: 10, wantMaxRun: 80},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.DatabaseConfig{UpsertCount: tt.upserts, UpsertConflictPercent: tt.percent, NewUsersCount: tt.newUsers}
+			emails := map[string]bool{}
+			conflicts, run, maxRun := 0, 0, 0
+			var last bool
+			for i := range tt.upserts {
+				u := upsertUser(cfg, i)
+				if emails[u.Email] {
+					t.Fatalf("email %s appears twice", u.Email)
+				}
+				emails[u.Email] = true
+
+				conflict := strings.HasPrefix(u.Email, "newuser")
+				if conflict {
+					conflicts++
+				}
+				if i > 0 && conflict == last {
+					run++
+				} else {
+					run = 1
+				}
+				last = conflict
+				maxRun = max(maxRun, run)
+			}
+			if conflicts != tt.wantConflicts {
+				t.Errorf("%d conflicts, want %d", conflicts, tt.wantConflicts)
+			}
+			if maxRun > tt.wantMaxRun {
+				t.Errorf("%d consecutive users of the same kind, want at most %d", maxRun, tt.wantMaxRun)
+			}
+		})
+	}
+}
+
+func TestParamChunks(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		params int
+		want   [][2]int
+	}{
+		{name: "empty", n: 0, params: 3, want: [][2]int{}},
+		{name: "one statement", n: 21845, params: 3, want: [][2]int{{0, 21845}}},
+		{name: "uuid keys", n: 20000, params: 4, want: [][2]int{{0, 16383}, {16383, 20000}}},
+		{name: "profiles", n: 30000, params: 5, want: [][2]int{{0, 13107}, {13107, 26214}, {26214, 30000}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParamChunks(tt.n, tt.params)
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("ParamChunks(%d, %d) = %v, want %v", tt.n, tt.params, got, tt.want)
+			}
+			for _, c := range got {
+				if (c[1]-c[0])*tt.params > MaxParams {
+					t.Errorf("chunk %v has more than %d parameters", c, MaxParams)
+				}
+			}
+		})
+	}
+}
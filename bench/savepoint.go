@@ -0,0 +1,84 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// --- Savepoint: Insert users in nested transactions, rolling some back ---
+func runSavepoint(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if cfg.SavepointCount <= 0 {
+		return PhaseResult{}, fmt.Errorf("disabled, enable with -savepoint-count: %w", errors.ErrUnsupported)
+	}
+	s, ok := d.(Savepointer)
+	if !ok {
+		return PhaseResult{}, fmt.Errorf("%s does not implement InsertNested: %w", d.Name(), errors.ErrUnsupported)
+	}
+
+	fmt.Fprintf(w, "\n=== Inserting %d users in nested transactions (%d%% rolled back) ===\n", cfg.SavepointCount, cfg.SavepointRollbackPercent)
+	before, err := d.Count(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	phase := PhaseResult{Count: cfg.SavepointCount}
+	key := newKey(cfg)
+	rolledBack := 0
+	savepointStart := time.Now()
+	for i := 0; i < cfg.SavepointCount; i += cfg.BatchSize {
+		batchStart := time.Now()
+		end := min(i+cfg.BatchSize, cfg.SavepointCount)
+
+		users := make([]User, 0, end-i)
+		rollback := make([]bool, 0, end-i)
+		for j := i; j < end; j++ {
+			u := User{
+				Name:      fmt.Sprintf("Savepoint_User_%06d", j+1),
+				Email:     fmt.Sprintf("savepointuser%06d@example.com", j+1),
+				CreatedAt: time.Now(),
+			}
+			if key != nil {
+				u.ID = key()
+			}
+			users = append(users, u)
+			// 割合どおりに均等に散らす
+			rb := (j+1)*cfg.SavepointRollbackPercent/100 > j*cfg.SavepointRollbackPercent/100
+			rollback = append(rollback, rb)
+			if rb {
+				rolledBack++
+			}
+		}
+
+		if err := s.InsertNested(ctx, users, rollback); err != nil {
+			return phase, fmt.Errorf("failed to insert users in nested transactions: batch %d-%d: %w", i+1, end, err)
+		}
+		batchDuration := time.Since(batchStart)
+		phase.Batches = append(phase.Batches, BatchResult{From: i + 1, To: end, Duration: batchDuration})
+		fmt.Fprintf(w, "Nested batch %d-%d committed in %v\n", i+1, end, batchDuration)
+	}
+	phase.Duration = time.Since(savepointStart)
+
+	// ロールバックした行が残っていないことを確認する
+	after, err := d.Count(ctx)
+	if err != nil {
+		return phase, fmt.Errorf("failed to count users: %w", err)
+	}
+	if want := cfg.SavepointCount - rolledBack; after-before != want {
+		return phase, fmt.Errorf("savepoint phase added %d users, want %d (%d of %d rolled back)", after-before, want, rolledBack, cfg.SavepointCount)
+	}
+
+	perSavepoint := float64(phase.Duration.Microseconds()) / float64(cfg.SavepointCount)
+	fmt.Fprintf(w, "Inserted %d users in nested transactions in %v (%d rolled back, %.1fµs per savepoint)\n",
+		cfg.SavepointCount, phase.Duration, rolledBack, perSavepoint)
+	phase.Stats = map[string]float64{
+		"Savepoints":       float64(cfg.SavepointCount),
+		"RolledBack":       float64(rolledBack),
+		"PerSavepoint(µs)": perSavepoint,
+	}
+	return phase, nil
+}
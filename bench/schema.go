@@ -0,0 +1,319 @@
+package bench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Schema describes the table of the Schema Insert phase, as loaded from the
+// -schema file. Every table also gets an id bigserial primary key, which is
+// not listed in Columns.
+type Schema struct {
+	Table   string   `json:"table"`
+	Columns []Column `json:"columns"`
+	Indexes []Index  `json:"indexes"`
+}
+
+// Column is one generated column. In the schema file, a column with Count
+// greater than 1 stands for Count columns named name_1 .. name_Count;
+// LoadSchema expands them, so a loaded Schema has Count 0 everywhere.
+type Column struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`             // PostgreSQLの型（schemaTypesのいずれか）
+	Length int    `json:"length,omitempty"` // 文字列の長さ（省略時はvarchar(n)のn、textは32）
+	Count  int    `json:"count,omitempty"`  // 同じ定義の列数
+}
+
+// Index is a secondary index on one or more columns.
+type Index struct {
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique,omitempty"`
+}
+
+// schemaTypes maps every supported column type, without its modifier such
+// as (12,2), to the generator of its values.
+var schemaTypes = map[string]func(c *Column, i int) any{
+	"smallint":         func(c *Column, i int) any { return int64(i % 32768) },
+	"integer":          func(c *Column, i int) any { return int64(i) },
+	"int":              func(c *Column, i int) any { return int64(i) },
+	"bigint":           func(c *Column, i int) any { return int64(i) * 1_000_003 },
+	"real":             func(c *Column, i int) any { return float64(i) / 8 },
+	"double precision": func(c *Column, i int) any { return float64(i) / 7 },
+	"numeric":          func(c *Column, i int) any { return float64(i%1_000_000) / 100 },
+	"boolean":          func(c *Column, i int) any { return i%2 == 0 },
+	"text":             genString,
+	"varchar":          genString,
+	"timestamptz":      func(c *Column, i int) any { return schemaEpoch.Add(time.Duration(i) * time.Second) },
+	"timestamp":        func(c *Column, i int) any { return schemaEpoch.Add(time.Duration(i) * time.Second) },
+	"date":             func(c *Column, i int) any { return schemaEpoch.AddDate(0, 0, i%3650) },
+}
+
+// schemaEpoch is the first generated timestamp.
+var schemaEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var (
+	identRe   = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	varcharRe = regexp.MustCompile(`^varchar\((\d+)\)$`)
+	// typeRe matches a whole column type: the type name with an optional
+	// (precision) or (precision,scale) modifier.
+	typeRe = regexp.MustCompile(`^([a-z]+(?: [a-z]+)*)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?$`)
+)
+
+// LoadSchema reads and validates the schema file at path.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file Schema
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	s := &Schema{Table: file.Table, Indexes: file.Indexes}
+	for _, c := range file.Columns {
+		if c.Count <= 1 {
+			c.Count = 0
+			s.Columns = append(s.Columns, c)
+			continue
+		}
+		for n := 1; n <= c.Count; n++ {
+			s.Columns = append(s.Columns, Column{Name: fmt.Sprintf("%s_%d", c.Name, n), Type: c.Type, Length: c.Length})
+		}
+	}
+	if err := s.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Schema) validate() error {
+	if !identRe.MatchString(s.Table) {
+		return fmt.Errorf("invalid table name %q (want lower case letters, digits and _)", s.Table)
+	}
+	if s.Table == "users" {
+		return errors.New("the schema table must not be users, which the other phases use")
+	}
+	if len(s.Columns) == 0 {
+		return errors.New("no columns")
+	}
+
+	names := map[string]bool{"id": true}
+	for i := range s.Columns {
+		c := &s.Columns[i]
+		if !identRe.MatchString(c.Name) {
+			return fmt.Errorf("invalid column name %q (want lower case letters, digits and _)", c.Name)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("duplicate column %q", c.Name)
+		}
+		names[c.Name] = true
+
+		typ, err := normalizeType(c.Type)
+		if err != nil {
+			return fmt.Errorf("column %q: %w", c.Name, err)
+		}
+		c.Type = typ
+		if m := varcharRe.FindStringSubmatch(c.Type); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			if c.Length == 0 || c.Length > n {
+				c.Length = n
+			}
+		}
+		if c.Length == 0 && (c.Type == "text" || c.Type == "varchar") {
+			c.Length = 32
+		}
+	}
+
+	for _, idx := range s.Indexes {
+		if len(idx.Columns) == 0 {
+			return errors.New("index without columns")
+		}
+		for _, name := range idx.Columns {
+			if !names[name] {
+				return fmt.Errorf("index on unknown column %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// normalizeType checks a column type and rebuilds it from its parsed name
+// and modifiers, so that only those reach the DDL of CreateStatements.
+func normalizeType(typ string) (string, error) {
+	m := typeRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(typ)))
+	if m == nil {
+		return "", fmt.Errorf("invalid type %q", typ)
+	}
+	if _, ok := schemaTypes[m[1]]; !ok {
+		return "", fmt.Errorf("unsupported type %q", typ)
+	}
+	switch {
+	case m[3] != "":
+		return fmt.Sprintf("%s(%s,%s)", m[1], m[2], m[3]), nil
+	case m[2] != "":
+		return fmt.Sprintf("%s(%s)", m[1], m[2]), nil
+	}
+	return m[1], nil
+}
+
+// ColumnNames returns the names of the generated columns, in order.
+func (s *Schema) ColumnNames() []string {
+	names := make([]string, len(s.Columns))
+	for i, c := range s.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// CreateStatements returns the statements that drop and recreate the table
+// with its indexes. The column types are the ones validate normalized.
+func (s *Schema) CreateStatements() []string {
+	table := pgx.Identifier{s.Table}.Sanitize()
+	defs := []string{"id BIGSERIAL PRIMARY KEY"}
+	for _, c := range s.Columns {
+		defs = append(defs, pgx.Identifier{c.Name}.Sanitize()+" "+strings.ToUpper(c.Type))
+	}
+
+	stmts := []string{
+		"DROP TABLE IF EXISTS " + table,
+		fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(defs, ", ")),
+	}
+	for i, idx := range s.Indexes {
+		cols := make([]string, len(idx.Columns))
+		for j, name := range idx.Columns {
+			cols[j] = pgx.Identifier{name}.Sanitize()
+		}
+		create := "CREATE INDEX"
+		if idx.Unique {
+			create = "CREATE UNIQUE INDEX"
+		}
+		name := pgx.Identifier{fmt.Sprintf("%s_idx%d", s.Table, i+1)}.Sanitize()
+		stmts = append(stmts, fmt.Sprintf("%s %s ON %s (%s)", create, name, table, strings.Join(cols, ", ")))
+	}
+	return stmts
+}
+
+// DropStatements returns the statement that removes the table after the
+// phase, so that its rows do not stay on disk.
+func (s *Schema) DropStatements() []string {
+	return []string{"DROP TABLE IF EXISTS " + pgx.Identifier{s.Table}.Sanitize()}
+}
+
+// InsertSQL returns a multi-row INSERT for n rows with $n placeholders.
+func (s *Schema) InsertSQL(n int) string {
+	cols := make([]string, len(s.Columns))
+	for i, name := range s.ColumnNames() {
+		cols[i] = pgx.Identifier{name}.Sanitize()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO %s (%s) VALUES ", pgx.Identifier{s.Table}.Sanitize(), strings.Join(cols, ", "))
+	arg := 1
+	for r := 0; r < n; r++ {
+		if r > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('(')
+		for c := range s.Columns {
+			if c > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "$%d", arg)
+			arg++
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// Row returns the values of the i-th (0-based) generated row, one per
+// column. Every value depends on i, so string columns are unique as long as
+// they are long enough to hold the row number.
+func (s *Schema) Row(i int) []any {
+	row := make([]any, len(s.Columns))
+	for j := range s.Columns {
+		c := &s.Columns[j]
+		row[j] = schemaTypes[baseType(c.Type)](c, i)
+	}
+	return row
+}
+
+// baseType strips the type modifier, e.g. "numeric(12,2)" -> "numeric".
+func baseType(typ string) string {
+	base, _, _ := strings.Cut(typ, "(")
+	return strings.TrimSpace(base)
+}
+
+// genString returns c.Length characters: the column name and row number,
+// repeated.
+func genString(c *Column, i int) any {
+	unit := fmt.Sprintf("%d_%s_", i, c.Name)
+	return strings.Repeat(unit, c.Length/len(unit)+1)[:c.Length]
+}
+
+// --- Schema Insert: Insert generated rows into the table of the -schema file ---
+func runSchema(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	if cfg.SchemaFile == "" {
+		return PhaseResult{}, fmt.Errorf("disabled, enable with -schema: %w", errors.ErrUnsupported)
+	}
+	ri, ok := d.(RowInserter)
+	if !ok {
+		return PhaseResult{}, fmt.Errorf("%s does not implement InsertRows: %w", d.Name(), errors.ErrUnsupported)
+	}
+	s, err := LoadSchema(cfg.SchemaFile)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	fmt.Fprintf(w, "\n=== Inserting %d rows into %s (%d columns, %d indexes) ===\n", cfg.SchemaRowsCount, s.Table, len(s.Columns), len(s.Indexes))
+	if err := ri.ExecSchema(ctx, s.CreateStatements()); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to create table %s: %w", s.Table, err)
+	}
+
+	// 1文あたりのパラメータ数の上限を超えないようにバッチを縮める
+	batchSize := min(cfg.BatchSize, MaxParams/len(s.Columns))
+	if batchSize < cfg.BatchSize {
+		fmt.Fprintf(w, "Batch size reduced to %d rows to stay within %d parameters\n", batchSize, MaxParams)
+	}
+
+	phase, err := insertRows(ctx, d, cfg, cfg.SchemaRowsCount, batchSize,
+		func(ctx context.Context, from, to int) error {
+			rows := make([][]any, 0, to-from)
+			for j := from; j < to; j++ {
+				rows = append(rows, s.Row(j))
+			}
+			return ri.InsertRows(ctx, s, rows)
+		},
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "Schema batch %d-%d inserted in %v\n", from, to, dur)
+		})
+	// 失敗した場合も挿入した行が残らないようテーブルを削除する
+	if derr := ri.ExecSchema(ctx, s.DropStatements()); derr != nil && err == nil {
+		return phase, fmt.Errorf("failed to drop table %s: %w", s.Table, derr)
+	}
+	if err != nil {
+		return phase, fmt.Errorf("failed to insert rows into %s: %w", s.Table, err)
+	}
+
+	rowsPerSec := float64(cfg.SchemaRowsCount) / phase.Duration.Seconds()
+	fmt.Fprintf(w, "Inserted %d rows into %s in %v (%.0f rows/s)\n", cfg.SchemaRowsCount, s.Table, phase.Duration, rowsPerSec)
+	phase.Stats = map[string]float64{
+		"Columns": float64(len(s.Columns)),
+		"Indexes": float64(len(s.Indexes)),
+		"Rows/s":  rowsPerSec,
+	}
+	return phase, nil
+}
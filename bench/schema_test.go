@@ -0,0 +1,115 @@
+package bench
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSchemaValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  Schema
+		wantErr string
+		// 検証後の列の型と長さ（wantErrが空の場合）
+		wantTypes   []string
+		wantLengths []int
+	}{
+		{
+			name: "valid",
+			schema: Schema{
+				Table: "orders",
+				Columns: []Column{
+					{Name: "amount", Type: " NUMERIC(12,2) "},
+					{Name: "code", Type: "varchar(12)"},
+					{Name: "note", Type: "text"},
+					{Name: "short", Type: "varchar(40)", Length: 8},
+					{Name: "long", Type: "varchar(8)", Length: 40},
+					{Name: "plain", Type: "varchar"},
+					{Name: "spaced", Type: "Numeric ( 10 , 3 )"},
+					{Name: "ratio", Type: "double precision"},
+				},
+				Indexes: []Index{{Columns: []string{"code"}, Unique: true}, {Columns: []string{"id", "amount"}}},
+			},
+			wantTypes:   []string{"numeric(12,2)", "varchar(12)", "text", "varchar(40)", "varchar(8)", "varchar", "numeric(10,3)", "double precision"},
+			wantLengths: []int{0, 12, 32, 8, 8, 32, 0, 0},
+		},
+		{name: "invalid table name", schema: Schema{Table: "Orders", Columns: []Column{{Name: "a", Type: "int"}}}, wantErr: "invalid table name"},
+		{name: "users table", schema: Schema{Table: "users", Columns: []Column{{Name: "a", Type: "int"}}}, wantErr: "must not be users"},
+		{name: "no columns", schema: Schema{Table: "orders"}, wantErr: "no columns"},
+		{name: "invalid column name", schema: Schema{Table: "orders", Columns: []Column{{Name: "a-b", Type: "int"}}}, wantErr: "invalid column name"},
+		{name: "id column", schema: Schema{Table: "orders", Columns: []Column{{Name: "id", Type: "int"}}}, wantErr: `duplicate column "id"`},
+		{
+			name:    "duplicate column",
+			schema:  Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "int"}, {Name: "a", Type: "text"}}},
+			wantErr: `duplicate column "a"`,
+		},
+		{name: "unsupported type", schema: Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "jsonb"}}}, wantErr: "unsupported type"},
+		{
+			name:    "sql after the modifier",
+			schema:  Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "numeric(12,2)); DROP TABLE users; --"}}},
+			wantErr: "invalid type",
+		},
+		{name: "sql in the modifier", schema: Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "varchar(1) DEFAULT now()"}}}, wantErr: "invalid type"},
+		{name: "non-numeric modifier", schema: Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "varchar(x)"}}}, wantErr: "invalid type"},
+		{
+			name:    "index without columns",
+			schema:  Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "int"}}, Indexes: []Index{{}}},
+			wantErr: "index without columns",
+		},
+		{
+			name:    "index on unknown column",
+			schema:  Schema{Table: "orders", Columns: []Column{{Name: "a", Type: "int"}}, Indexes: []Index{{Columns: []string{"b"}}}},
+			wantErr: "unknown column",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.schema
+			err := s.validate()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("validate returned %v, want an error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i, c := range s.Columns {
+				if c.Type != tt.wantTypes[i] || c.Length != tt.wantLengths[i] {
+					t.Errorf("column %s has type %q and length %d, want %q and %d", c.Name, c.Type, c.Length, tt.wantTypes[i], tt.wantLengths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSchemaStatements(t *testing.T) {
+	s := Schema{
+		Table:   "orders",
+		Columns: []Column{{Name: "amount", Type: "Numeric ( 12 , 2 )"}, {Name: "code", Type: "varchar(12)"}},
+		Indexes: []Index{{Columns: []string{"code"}, Unique: true}},
+	}
+	if err := s.validate(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`DROP TABLE IF EXISTS "orders"`,
+		`CREATE TABLE "orders" (id BIGSERIAL PRIMARY KEY, "amount" NUMERIC(12,2), "code" VARCHAR(12))`,
+		`CREATE UNIQUE INDEX "orders_idx1" ON "orders" ("code")`,
+	}
+	if got := s.CreateStatements(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CreateStatements = %q, want %q", got, want)
+	}
+	if got := s.DropStatements(); len(got) != 1 || got[0] != want[0] {
+		t.Errorf("DropStatements = %q, want %q", got, want[:1])
+	}
+}
+
+func TestLoadSchemaFiles(t *testing.T) {
+	for _, path := range []string{"../schemas/users.json", "../schemas/wide.json"} {
+		if _, err := LoadSchema(path); err != nil {
+			t.Errorf("LoadSchema(%q) returned %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// PrintSummary writes the performance summary of a single run.
+func PrintSummary(w io.Writer, r *Result) {
+	fmt.Fprintln(w, "\n==================================================")
+	fmt.Fprintf(w, "%s PERFORMANCE SUMMARY\n", strings.ToUpper(r.Driver))
+	fmt.Fprintln(w, "==================================================")
+	for _, p := range r.Phases {
+		fmt.Fprintf(w, "%-16s%v\n", phaseLabel(r, p.Name)+":", p.Duration)
+		if len(p.Stats) > 0 {
+			keys := make([]string, 0, len(p.Stats))
+			for k := range p.Stats {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			fmt.Fprint(w, "               ")
+			for _, k := range keys {
+				fmt.Fprintf(w, " %s=%g", k, p.Stats[k])
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	fmt.Fprintln(w, "--------------------------------------------------")
+	fmt.Fprintf(w, "TOTAL TIME:     %v\n", r.Total)
+	fmt.Fprintln(w, "==================================================")
+}
+
+// PrintComparison writes one table comparing the phase timings of several
+// runs side by side, in milliseconds.
+func PrintComparison(w io.Writer, results []*Result) {
+	if len(results) == 0 {
+		return
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprint(tw, "Phase\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s (ms)\t", r.Driver)
+	}
+	fmt.Fprintln(tw)
+
+	for _, name := range phaseNames(results) {
+		fmt.Fprintf(tw, "%s\t", phaseLabel(results[0], name))
+		for _, r := range results {
+			if rp := r.Phase(name); rp != nil {
+				fmt.Fprintf(tw, "%s\t", millis(rp.Duration))
+			} else {
+				fmt.Fprint(tw, "-\t")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+
+	fmt.Fprint(tw, "Total Time\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t", millis(r.Total))
+	}
+	fmt.Fprintln(tw)
+	tw.Flush()
+}
+
+// PrintSweep writes one row per configuration of a parameter sweep, keyed by
+// the swept values, with the total time of every driver in milliseconds.
+// Runs that differ only in driver options, such as the GORM matrix, share
+// a row and appear as separate driver columns. A table of the same shape
+// follows for each of the named phases, or for every phase when phases is
+// ["all"], since phases that the swept values do not touch can hide the
+// knee of the others in the total.
+func PrintSweep(w io.Writer, results []*Result, keys []config.Field, phases []string) {
+	var rows []*Result
+	var drivers []string
+	byKey := map[string]map[string]*Result{}
+	for _, r := range results {
+		key := sweepKey(r, keys)
+		if byKey[key] == nil {
+			byKey[key] = map[string]*Result{}
+			rows = append(rows, r)
+		}
+		if indexOf(drivers, r.Driver) < 0 {
+			drivers = append(drivers, r.Driver)
+		}
+		byKey[key][r.Driver] = r
+	}
+
+	table := func(title string, value func(r *Result) (time.Duration, bool)) {
+		fmt.Fprintf(w, "\n%s\n", title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+		for _, k := range keys {
+			fmt.Fprintf(tw, "%s\t", k.Flag)
+		}
+		for _, d := range drivers {
+			fmt.Fprintf(tw, "%s (ms)\t", d)
+		}
+		fmt.Fprintln(tw)
+
+		for _, row := range rows {
+			for _, k := range keys {
+				fmt.Fprintf(tw, "%d\t", *k.Ptr(&row.Config))
+			}
+			for _, d := range drivers {
+				r := byKey[sweepKey(row, keys)][d]
+				if r == nil {
+					fmt.Fprint(tw, "-\t")
+					continue
+				}
+				if v, ok := value(r); ok {
+					fmt.Fprintf(tw, "%s\t", millis(v))
+				} else {
+					fmt.Fprint(tw, "-\t")
+				}
+			}
+			fmt.Fprintln(tw)
+		}
+		tw.Flush()
+	}
+
+	table("Total Time", func(r *Result) (time.Duration, bool) { return r.Total, true })
+	if len(phases) == 1 && phases[0] == "all" {
+		phases = phaseNames(results)
+	}
+	for _, name := range phases {
+		table(name, func(r *Result) (time.Duration, bool) {
+			if rp := r.Phase(name); rp != nil {
+				return rp.Duration, true
+			}
+			return 0, false
+		})
+	}
+}
+
+// sweepKey identifies the swept values of a run, e.g. "500,1000".
+func sweepKey(r *Result, keys []config.Field) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = strconv.Itoa(*k.Ptr(&r.Config))
+	}
+	return strings.Join(parts, ",")
+}
+
+// PrintRanking writes one row per run, fastest total first, with the phase
+// timings as columns. It suits comparing many variants of one driver, where
+// PrintComparison would grow too wide.
+func PrintRanking(w io.Writer, results []*Result) {
+	if len(results) == 0 {
+		return
+	}
+	sorted := append([]*Result(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Total < sorted[j].Total })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	names := phaseNames(results)
+	fmt.Fprint(tw, "Driver\t")
+	for _, name := range names {
+		fmt.Fprintf(tw, "%s (ms)\t", name)
+	}
+	fmt.Fprintln(tw, "Total (ms)\t")
+
+	for _, r := range sorted {
+		fmt.Fprintf(tw, "%s\t", r.Driver)
+		for _, name := range names {
+			if rp := r.Phase(name); rp != nil {
+				fmt.Fprintf(tw, "%s\t", millis(rp.Duration))
+			} else {
+				fmt.Fprint(tw, "-\t")
+			}
+		}
+		fmt.Fprintf(tw, "%s\t\n", millis(r.Total))
+	}
+	tw.Flush()
+}
+
+// phaseNames returns the phases recorded by any of results, in the order
+// Run executes them, so that a phase only some drivers support still gets
+// a row.
+func phaseNames(results []*Result) []string {
+	var names []string
+	for _, r := range results {
+		for _, p := range r.Phases {
+			if indexOf(names, p.Name) < 0 {
+				names = append(names, p.Name)
+			}
+		}
+	}
+	sortPhaseNames(names)
+	return names
+}
+
+// sortPhaseNames orders names as Phases does, unknown names last.
+func sortPhaseNames(names []string) {
+	order := func(name string) int {
+		for i, p := range Phases {
+			if p.Name == name {
+				return i
+			}
+		}
+		return len(Phases)
+	}
+	sort.SliceStable(names, func(i, j int) bool { return order(names[i]) < order(names[j]) })
+}
+
+// phaseLabel returns the summary label of a phase, including the number of
+// rows for the bulk phases, e.g. "Seed (50000)".
+func phaseLabel(r *Result, name string) string {
+	switch name {
+	case PhaseSeed:
+		if k := r.Config.KeyStrategy; k != "" && k != config.KeySerial {
+			return fmt.Sprintf("%s (%d, %s)", name, r.Config.InitialUsersCount, k)
+		}
+		return fmt.Sprintf("%s (%d)", name, r.Config.InitialUsersCount)
+	case PhaseUpdate:
+		return fmt.Sprintf("%s (%d)", name, r.Config.UpdateCount)
+	case PhaseDelete:
+		return fmt.Sprintf("%s (%d)", name, r.Config.DeleteCount)
+	case PhaseCreate:
+		return fmt.Sprintf("%s (%d)", name, r.Config.NewUsersCount)
+	case PhaseUpsert:
+		return fmt.Sprintf("%s (%d)", name, r.Config.UpsertCount)
+	case PhaseSavepoint:
+		return fmt.Sprintf("%s (%d)", name, r.Config.SavepointCount)
+	case PhaseContention:
+		return fmt.Sprintf("%s (%dx%d)", name, r.Config.ContentionClients, r.Config.ContentionTransactions)
+	case PhaseSchema:
+		return fmt.Sprintf("%s (%d)", name, r.Config.SchemaRowsCount)
+	case PhaseJSONBInsert, PhaseJSONBUpdate:
+		return fmt.Sprintf("%s (%d)", name, r.Config.JsonbCount)
+	case PhaseTypesInsert, PhaseTypesRead:
+		return fmt.Sprintf("%s (%d)", name, r.Config.TypesCount)
+	case PhaseBytea, PhaseLargeObject:
+		return fmt.Sprintf("%s (%dx%s)", name, r.Config.BlobCount, formatBytes(r.Config.BlobSize))
+	case PhaseRelationsSeed:
+		return fmt.Sprintf("%s (%dx%d)", name, r.Config.PostsPerUser, r.Config.CommentsPerPost)
+	case PhaseRelationsNaive, PhaseRelationsPreload, PhaseRelationsJoin:
+		return fmt.Sprintf("%s (%d)", name, r.Config.RelationsUsers)
+	case PhaseJSONBQuery:
+		return fmt.Sprintf("%s (%dx)", name, r.Config.JsonbQueryCount)
+	}
+	return name
+}
+
+// formatBytes formats a size with a binary unit, e.g. 1048576 -> "1MB".
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	}
+	return fmt.Sprintf("%dB", n)
+}
+
+func millis(d time.Duration) string {
+	return fmt.Sprintf("%.1f", float64(d)/float64(time.Millisecond))
+}
+
+// WriteResults stores results as a JSON array in the file at path.
+func WriteResults(path string, results []*Result) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// ReadResults loads results written by WriteResults.
+func ReadResults(path string) ([]*Result, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var results []*Result
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,74 @@
+package bench
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Transactor is implemented by drivers that can run phases inside a
+// transaction with their library's native API. The Driver methods called
+// from fn must run on that transaction.
+type Transactor interface {
+	InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error
+}
+
+// TxOptions returns the options of the transactions requested by cfg, or
+// nil when cfg.TxScope is none.
+func TxOptions(cfg *config.DatabaseConfig) (*sql.TxOptions, error) {
+	switch cfg.TxScope {
+	case "", config.TxScopeNone:
+		return nil, nil
+	case config.TxScopeBatch, config.TxScopePhase:
+	default:
+		return nil, fmt.Errorf("unknown tx scope %q (available: %v)", cfg.TxScope, config.TxScopes)
+	}
+
+	var level sql.IsolationLevel
+	switch cfg.Isolation {
+	case "", "read-committed":
+		level = sql.LevelReadCommitted
+	case "repeatable-read":
+		level = sql.LevelRepeatableRead
+	case "serializable":
+		level = sql.LevelSerializable
+	default:
+		return nil, fmt.Errorf("unknown isolation level %q (available: %v)", cfg.Isolation, config.IsolationLevels)
+	}
+	return &sql.TxOptions{Isolation: level}, nil
+}
+
+// PgxTxOptions converts opts for the BeginTx methods of pgx.
+func PgxTxOptions(opts *sql.TxOptions) pgx.TxOptions {
+	var txOptions pgx.TxOptions
+	switch opts.Isolation {
+	case sql.LevelRepeatableRead:
+		txOptions.IsoLevel = pgx.RepeatableRead
+	case sql.LevelSerializable:
+		txOptions.IsoLevel = pgx.Serializable
+	default:
+		txOptions.IsoLevel = pgx.ReadCommitted
+	}
+	return txOptions
+}
+
+// withTx runs fn in a transaction of d when cfg.TxScope is one of scopes,
+// and directly otherwise.
+func withTx(ctx context.Context, d Driver, cfg *config.DatabaseConfig, fn func(ctx context.Context) error, scopes ...string) error {
+	opts, err := TxOptions(cfg)
+	if err != nil {
+		return err
+	}
+	if opts == nil || indexOf(scopes, cfg.TxScope) < 0 {
+		return fn(ctx)
+	}
+	t, ok := d.(Transactor)
+	if !ok {
+		return fmt.Errorf("%s does not support -tx-scope", d.Name())
+	}
+	return t.InTx(ctx, opts, fn)
+}
@@ -0,0 +1,300 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-postgresql/config"
+)
+
+// TypeRow is one row of the type_samples table of the Types phases. Its
+// fields use the Go types a caller would naturally hold; every driver maps
+// them onto its library's types for the column.
+type TypeRow struct {
+	UID        [16]byte      // uuid
+	Amount     string        // numeric(20,6)、精度を保つため10進数の文字列
+	IDs        []int64       // int8[]
+	Labels     []string      // text[]
+	Payload    []byte        // bytea
+	Addr       netip.Addr    // inet
+	Span       time.Duration // interval（マイクロ秒単位）
+	HappenedAt time.Time     // timestamptz（UTC以外のタイムゾーンで生成）
+	Note       *string       // text、NULLあり
+	Quantity   *int64        // int8、NULLあり
+}
+
+// typeColumns are the columns of type_samples, in TypeRow order.
+var typeColumns = []string{"uid", "amount", "ids", "labels", "payload", "addr", "span", "happened_at", "note", "quantity"}
+
+// typeStatements recreate the type_samples table.
+var typeStatements = []string{
+	"DROP TABLE IF EXISTS type_samples",
+	`CREATE TABLE type_samples (
+		id BIGSERIAL PRIMARY KEY,
+		uid UUID NOT NULL,
+		amount NUMERIC(20,6) NOT NULL,
+		ids INT8[] NOT NULL,
+		labels TEXT[] NOT NULL,
+		payload BYTEA NOT NULL,
+		addr INET NOT NULL,
+		span INTERVAL NOT NULL,
+		happened_at TIMESTAMPTZ NOT NULL,
+		note TEXT,
+		quantity INT8
+	)`,
+}
+
+// TypeRowsInsertSQL returns a multi-row INSERT into type_samples for n rows
+// with $n placeholders, in TypeRow field order. The drivers share it so that
+// only the encoding of the values differs.
+func TypeRowsInsertSQL(n int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "INSERT INTO type_samples (%s) VALUES ", strings.Join(typeColumns, ", "))
+	arg := 1
+	for r := 0; r < n; r++ {
+		if r > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('(')
+		for c := range typeColumns {
+			if c > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "$%d", arg)
+			arg++
+		}
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// TypeRowsSelectSQL reads every row of type_samples in insertion order.
+var TypeRowsSelectSQL = fmt.Sprintf("SELECT %s FROM type_samples ORDER BY id", strings.Join(typeColumns, ", "))
+
+// typeZones alternate so that a driver that drops the offset shows up.
+var typeZones = []*time.Location{time.FixedZone("JST", 9*60*60), time.FixedZone("EST", -5*60*60)}
+
+// newTypeRow returns the i-th (0-based) row of the Types phases. The values
+// are chosen to catch lossy mappings: 20 significant numeric digits, array
+// elements that need quoting, bytes 0x00 and 0xff, IPv4 and IPv6, NULL next
+// to the empty string, and times in two zones.
+func newTypeRow(i int) TypeRow {
+	var uid [16]byte
+	binary.BigEndian.PutUint64(uid[:8], uint64(i)*0x9e3779b97f4a7c15)
+	binary.BigEndian.PutUint64(uid[8:], uint64(i))
+	uid[6] = uid[6]&0x0f | 0x40 // version 4
+	uid[8] = uid[8]&0x3f | 0x80 // RFC 4122 variant
+
+	payload := make([]byte, 64+i%64)
+	for k := range payload {
+		payload[k] = byte(i + k*31)
+	}
+
+	addr := netip.AddrFrom4([4]byte{10, byte(i >> 16), byte(i >> 8), byte(i)})
+	if i%2 == 1 {
+		addr = netip.AddrFrom16([16]byte{0x20, 0x01, 0x0d, 0xb8, 12: byte(i >> 24), byte(i >> 16), byte(i >> 8), byte(i)})
+	}
+
+	row := TypeRow{
+		UID:        uid,
+		Amount:     fmt.Sprintf("%d.%06d", (int64(i)*1_000_003)%100_000_000_000_000, (i*7919)%1_000_000),
+		IDs:        []int64{int64(i), int64(i) << 33, math.MinInt64 + int64(i)},
+		Labels:     []string{fmt.Sprintf("label %d", i), `quote " and \ backslash`, "{brace,comma}", "NULL", ""},
+		Payload:    payload,
+		Addr:       addr,
+		Span:       time.Duration(i%172800)*time.Second + time.Duration(i%1000)*time.Microsecond,
+		HappenedAt: time.Date(2024, 3, 10, 2, 30, 0, (i%1_000_000)*1000, typeZones[i%len(typeZones)]).Add(time.Duration(i) * time.Minute),
+	}
+	switch i % 3 {
+	case 1:
+		empty := ""
+		row.Note = &empty
+	case 2:
+		note := fmt.Sprintf("note %d", i)
+		row.Note = &note
+	}
+	if i%4 != 0 {
+		q := int64(i) * 1_000_000_007
+		row.Quantity = &q
+	}
+	return row
+}
+
+// diffTypeRow returns the name of the first column that did not survive
+// the round trip, or "" when got equals want.
+func diffTypeRow(want, got TypeRow) string {
+	wantAmount, _ := new(big.Rat).SetString(want.Amount)
+	gotAmount, ok := new(big.Rat).SetString(got.Amount)
+	switch {
+	case want.UID != got.UID:
+		return "uid"
+	case !ok || wantAmount.Cmp(gotAmount) != 0:
+		return "amount"
+	case !slices.Equal(want.IDs, got.IDs):
+		return "ids"
+	case !slices.Equal(want.Labels, got.Labels):
+		return "labels"
+	case !bytes.Equal(want.Payload, got.Payload):
+		return "payload"
+	case want.Addr != got.Addr:
+		return "addr"
+	case want.Span != got.Span:
+		return "span"
+	case !want.HappenedAt.Equal(got.HappenedAt):
+		return "happened_at"
+	case (want.Note == nil) != (got.Note == nil) || want.Note != nil && *want.Note != *got.Note:
+		return "note"
+	case (want.Quantity == nil) != (got.Quantity == nil) || want.Quantity != nil && *want.Quantity != *got.Quantity:
+		return "quantity"
+	}
+	return ""
+}
+
+// typeMapper returns d as a TypeMapper, or an error wrapping
+// errors.ErrUnsupported when the Types phases are disabled or d lacks them.
+func typeMapper(d Driver, cfg *config.DatabaseConfig) (TypeMapper, error) {
+	if cfg.TypesCount <= 0 {
+		return nil, fmt.Errorf("disabled, enable with -types-count: %w", errors.ErrUnsupported)
+	}
+	tm, ok := d.(TypeMapper)
+	if !ok {
+		return nil, fmt.Errorf("%s does not implement the Types phases: %w", d.Name(), errors.ErrUnsupported)
+	}
+	return tm, nil
+}
+
+// --- Types Insert: Encode rows of uuid, numeric, array, bytea, inet and interval columns ---
+func runTypesInsert(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	tm, err := typeMapper(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+
+	fmt.Fprintf(w, "\n=== Inserting %d rows into type_samples ===\n", cfg.TypesCount)
+	if err := tm.ExecSchema(ctx, typeStatements); err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to create table type_samples: %w", err)
+	}
+
+	batchSize := min(cfg.BatchSize, MaxParams/len(typeColumns))
+	insert, err := insertRows(ctx, d, cfg, cfg.TypesCount, batchSize,
+		func(ctx context.Context, from, to int) error {
+			rows := make([]TypeRow, 0, to-from)
+			for j := from; j < to; j++ {
+				rows = append(rows, newTypeRow(j))
+			}
+			return tm.InsertTypeRows(ctx, rows)
+		},
+		func(from, to int, dur time.Duration) {
+			fmt.Fprintf(w, "Types batch %d-%d inserted in %v\n", from, to, dur)
+		})
+	if err != nil {
+		return insert, fmt.Errorf("failed to insert into type_samples: %w", err)
+	}
+
+	perRow := float64(insert.Duration.Microseconds()) / float64(cfg.TypesCount)
+	fmt.Fprintf(w, "Inserted %d rows in %v (%.1fµs per row)\n", cfg.TypesCount, insert.Duration, perRow)
+	insert.Stats = map[string]float64{"PerRow(µs)": perRow}
+	return insert, nil
+}
+
+// --- Types Read: Decode the rows again and check that every value survived ---
+func runTypesRead(ctx context.Context, d Driver, cfg *config.DatabaseConfig, w io.Writer) (PhaseResult, error) {
+	tm, err := typeMapper(d, cfg)
+	if err != nil {
+		return PhaseResult{}, err
+	}
+
+	fmt.Fprintf(w, "\n=== Reading back %d rows from type_samples ===\n", cfg.TypesCount)
+	readStart := time.Now()
+	rows, err := tm.ReadTypeRows(ctx)
+	if err != nil {
+		return PhaseResult{}, fmt.Errorf("failed to read type_samples: %w", err)
+	}
+	readDuration := time.Since(readStart)
+	if len(rows) != cfg.TypesCount {
+		return PhaseResult{}, fmt.Errorf("read %d rows from type_samples, want %d", len(rows), cfg.TypesCount)
+	}
+
+	// 値の欠落は中断せずに列ごとに数えて報告する
+	mismatches := map[string]int{}
+	for i, got := range rows {
+		if col := diffTypeRow(newTypeRow(i), got); col != "" {
+			if mismatches[col] == 0 {
+				fmt.Fprintf(w, "Row %d: %s did not round-trip: got %+v\n", i+1, col, got)
+			}
+			mismatches[col]++
+		}
+	}
+
+	perRow := float64(readDuration.Microseconds()) / float64(len(rows))
+	stats := map[string]float64{"PerRow(µs)": perRow, "Mismatches": 0}
+	for col, n := range mismatches {
+		stats["Mismatches"] += float64(n)
+		stats["Mismatch["+col+"]"] = float64(n)
+	}
+	fmt.Fprintf(w, "Read %d rows in %v (%.1fµs per row, %g mismatched)\n", len(rows), readDuration, perRow, stats["Mismatches"])
+	return PhaseResult{Count: len(rows), Duration: readDuration, Stats: stats}, nil
+}
+
+// ParseUUID parses the text form of a uuid, as FormatUUID writes it.
+func ParseUUID(s string) ([16]byte, error) {
+	var u [16]byte
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil || len(b) != len(u) {
+		return u, fmt.Errorf("invalid uuid %q", s)
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+// ParseInterval parses the text form of an interval without months, such
+// as "1 day 02:03:04.000005" or "26:03:04", for the drivers that read the
+// interval column of the Types phases as a string.
+func ParseInterval(s string) (time.Duration, error) {
+	fields := strings.Fields(s)
+	var d time.Duration
+	for len(fields) >= 2 && strings.HasPrefix(fields[1], "day") {
+		days, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q", s)
+		}
+		d += time.Duration(days) * 24 * time.Hour
+		fields = fields[2:]
+	}
+	if len(fields) == 0 {
+		return d, nil
+	}
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+
+	clock := fields[0]
+	sign := time.Duration(1)
+	if rest, ok := strings.CutPrefix(clock, "-"); ok {
+		sign, clock = -1, rest
+	}
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	hours, err1 := strconv.Atoi(parts[0])
+	minutes, err2 := strconv.Atoi(parts[1])
+	seconds, err3 := strconv.ParseFloat(parts[2], 64)
+	if err1 != nil || err2 != nil || err3 != nil {
+		return 0, fmt.Errorf("invalid interval %q", s)
+	}
+	micros := time.Duration(seconds*1e6+0.5) * time.Microsecond
+	return d + sign*(time.Duration(hours)*time.Hour+time.Duration(minutes)*time.Minute+micros), nil
+}
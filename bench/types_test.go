@@ -0,0 +1,79 @@
+package bench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "00:00:00", want: 0},
+		{in: "26:03:04", want: 26*time.Hour + 3*time.Minute + 4*time.Second},
+		{in: "1 day 02:03:04.000005", want: 26*time.Hour + 3*time.Minute + 4*time.Second + 5*time.Microsecond},
+		{in: "3 days", want: 72 * time.Hour},
+		{in: "-1 days", want: -24 * time.Hour},
+		{in: "1 day -01:00:00", want: 23 * time.Hour},
+		{in: "00:00:01.5", want: 1500 * time.Millisecond},
+		{in: "", want: 0},
+		{in: "1 mon", wantErr: true},
+		{in: "x days", wantErr: true},
+		{in: "01:02", wantErr: true},
+		{in: "01:02:xx", wantErr: true},
+		{in: "1 day 01:02:03 extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseInterval(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseInterval(%q) returned %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseInterval(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	want := [16]byte{0x01, 0x90, 0xa0, 0xb1, 0xc2, 0xd3, 0x7e, 0xf4, 0x85, 0x96, 0xa7, 0xb8, 0xc9, 0xda, 0xeb, 0xfc}
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: "0190a0b1-c2d3-7ef4-8596-a7b8c9daebfc"},
+		{in: "0190A0B1-C2D3-7EF4-8596-A7B8C9DAEBFC"},
+		{in: "0190a0b1c2d37ef48596a7b8c9daebfc"},
+		{in: "", wantErr: true},
+		{in: "0190a0b1-c2d3-7ef4-8596-a7b8c9daeb", wantErr: true},
+		{in: "0190a0b1-c2d3-7ef4-8596-a7b8c9daebfc00", wantErr: true},
+		{in: "0190a0b1-c2d3-7ef4-8596-a7b8c9daebzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseUUID(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseUUID(%q) = %x, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseUUID(%q) returned %v", tt.in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseUUID(%q) = %x, want %x", tt.in, got, want)
+		}
+	}
+	if s := FormatUUID(want); s != tests[0].in {
+		t.Errorf("FormatUUID = %q, want %q", s, tests[0].in)
+	}
+}
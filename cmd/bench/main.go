@@ -0,0 +1,283 @@
+// Command bench starts PostgreSQL, waits until it accepts connections and
+// runs every selected driver, either in-process or as a prebuilt cmd/*
+// binary, then prints one comparison table of all runs.
+//
+// Every DatabaseConfig field has a flag that accepts a list or range of
+// values, e.g. -batch-size=100,500,1000..5000:1000. When any field has more
+// than one value, every combination is run for every driver (sweep mode)
+// and the table is keyed by the swept values.
+//
+// With -gorm-matrix, every GORM driver is additionally run once per
+// combination of its gorm.Config tuning options (see config.GormMatrix),
+// and each combination is reported as its own variant. Likewise
+// -pgx-exec-mode accepts a list of pgx query exec modes, or "all", the
+// default, and runs the pgx and pgxpool drivers once per mode.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	_ "go-postgresql/driver/bundriver"
+	_ "go-postgresql/driver/entdriver"
+	_ "go-postgresql/driver/gormdriver"
+	_ "go-postgresql/driver/pgxdriver"
+	_ "go-postgresql/driver/pqdriver"
+	_ "go-postgresql/driver/sqlcdriver"
+	_ "go-postgresql/driver/sqlxdriver"
+	_ "go-postgresql/driver/squirreldriver"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func main() {
+	driverList := flag.String("drivers", "gorm,pgx,pq", "comma separated drivers to run, from: "+strings.Join(bench.Drivers(), ","))
+	mode := flag.String("mode", "inprocess", "how to run each driver: inprocess or binary")
+	binDir := flag.String("bin-dir", "bin", "directory holding the prebuilt cmd/* binaries (binary mode)")
+	compose := flag.Bool("compose", true, "start the PostgreSQL container with docker compose first")
+	wait := flag.Duration("wait", time.Minute, "how long to wait for PostgreSQL to become ready")
+	jsonPath := flag.String("json", "", "also write all results as JSON to this file")
+	gormMatrix := flag.Bool("gorm-matrix", false, "run the GORM drivers with every combination of the gorm-* tuning options")
+	sweepPhases := flag.String("sweep-phase", bench.PhaseSeed+","+bench.PhaseCreate, "comma separated phases shown after the total in a sweep, or all")
+
+	cfg := config.GetConfig()
+	// pgxとpgxpoolはデフォルトで全モードを実行し、モードごとに集計する
+	cfg.PgxQueryExecMode = "all"
+	sweepFlags := map[string]*string{}
+	for _, f := range config.Fields {
+		sweepFlags[f.Flag] = flag.String(f.Flag, strconv.Itoa(*f.Ptr(cfg)), f.Usage+" (list or range start..end:step to sweep)")
+	}
+	config.RegisterOptionFlags(flag.CommandLine, cfg)
+	flag.Parse()
+
+	values := map[string][]int{}
+	var swept []config.Field
+	for _, f := range config.Fields {
+		vs, err := config.ParseValues(*sweepFlags[f.Flag])
+		if err != nil {
+			log.Fatalf("Invalid -%s: %v", f.Flag, err)
+		}
+		values[f.Flag] = vs
+		if len(vs) > 1 {
+			swept = append(swept, f)
+		}
+	}
+	configs := config.Sweep(*cfg, values)
+	for i := range configs {
+		if err := configs[i].Validate(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	phases := strings.Split(*sweepPhases, ",")
+	for i, name := range phases {
+		phases[i] = strings.TrimSpace(name)
+		if _, ok := bench.LookupPhase(phases[i]); !ok && phases[i] != "all" {
+			log.Fatalf("Unknown -sweep-phase %q", phases[i])
+		}
+	}
+
+	execModes := strings.Split(cfg.PgxQueryExecMode, ",")
+	if cfg.PgxQueryExecMode == "all" {
+		execModes = config.QueryExecModes
+	}
+
+	if *mode != "inprocess" && *mode != "binary" {
+		log.Fatalf("Unknown mode %q (want inprocess or binary)", *mode)
+	}
+
+	fmt.Println("==========================================")
+	fmt.Println("PostgreSQL Performance Benchmark")
+	fmt.Println("==========================================")
+
+	ctx := context.Background()
+	dsn := config.DSN()
+
+	if *compose {
+		if err := startPostgres(ctx); err != nil {
+			log.Fatalf("Failed to start PostgreSQL container: %v", err)
+		}
+	}
+
+	fmt.Println("Waiting for PostgreSQL to be ready...")
+	waitCtx, cancel := context.WithTimeout(ctx, *wait)
+	err := waitForPostgres(waitCtx, dsn)
+	cancel()
+	if err != nil {
+		log.Fatalf("PostgreSQL did not become ready within %v: %v", *wait, err)
+	}
+	fmt.Println("PostgreSQL is ready!")
+
+	var results []*bench.Result
+	hasVariants := false // いずれかのドライバーを複数のバリアントで実行した
+	for i := range configs {
+		for _, name := range strings.Split(*driverList, ",") {
+			name = strings.TrimSpace(name)
+			runs := variants(name, configs[i], *gormMatrix, execModes)
+			hasVariants = hasVariants || len(runs) > 1
+			for j := range runs {
+				c := &runs[j]
+				fmt.Println("\n==========================================")
+				fmt.Printf("Running %s%s...\n", name, sweepLabel(c, swept))
+				fmt.Println("==========================================")
+
+				var res *bench.Result
+				if *mode == "binary" {
+					res, err = runBinary(ctx, *binDir, name, c)
+				} else {
+					res, err = runInProcess(ctx, name, dsn, c)
+				}
+				if err != nil {
+					log.Fatalf("%s: %v", name, err)
+				}
+				results = append(results, res)
+			}
+		}
+	}
+
+	fmt.Println("\n==========================================")
+	fmt.Println("Benchmark Complete!")
+	fmt.Println("==========================================")
+	if len(swept) > 0 {
+		bench.PrintSweep(os.Stdout, results, swept, phases)
+	} else if hasVariants {
+		bench.PrintRanking(os.Stdout, results)
+	} else {
+		bench.PrintComparison(os.Stdout, results)
+	}
+
+	if *jsonPath != "" {
+		if err := bench.WriteResults(*jsonPath, results); err != nil {
+			log.Fatalf("Failed to write results: %v", err)
+		}
+		fmt.Printf("\nResults written to %s\n", *jsonPath)
+	}
+}
+
+// variants returns the configurations to run the named driver with: every
+// GORM tuning combination for the GORM drivers when gormMatrix is set, one
+// per exec mode for the native pgx drivers, and cfg alone otherwise.
+func variants(name string, cfg config.DatabaseConfig, gormMatrix bool, execModes []string) []config.DatabaseConfig {
+	switch {
+	case gormMatrix && strings.HasPrefix(name, "gorm"):
+		return config.GormMatrix(cfg)
+	case name == "pgx" || name == "pgxpool":
+		runs := make([]config.DatabaseConfig, len(execModes))
+		for i, m := range execModes {
+			runs[i] = cfg
+			runs[i].PgxQueryExecMode = strings.TrimSpace(m)
+		}
+		return runs
+	}
+	return []config.DatabaseConfig{cfg}
+}
+
+// sweepLabel describes the swept values of cfg, e.g. " (batch-size=500)".
+func sweepLabel(cfg *config.DatabaseConfig, swept []config.Field) string {
+	if len(swept) == 0 {
+		return ""
+	}
+	parts := make([]string, len(swept))
+	for i, f := range swept {
+		parts[i] = fmt.Sprintf("%s=%d", f.Flag, *f.Ptr(cfg))
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// startPostgres brings up the compose services, which is a no-op when the
+// container is already running.
+func startPostgres(ctx context.Context) error {
+	if err := exec.CommandContext(ctx, "docker", "info").Run(); err != nil {
+		return errors.New("docker is not running, please start Docker first or pass -compose=false")
+	}
+	fmt.Println("Starting PostgreSQL container...")
+	cmd := exec.CommandContext(ctx, "docker", "compose", "up", "-d")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// waitForPostgres retries connecting until the server answers a ping or ctx
+// expires.
+func waitForPostgres(ctx context.Context, dsn string) error {
+	for {
+		conn, err := pgx.Connect(ctx, dsn)
+		if err == nil {
+			err = conn.Ping(ctx)
+			conn.Close(ctx)
+			if err == nil {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(2 * time.Second):
+			fmt.Println("Waiting for PostgreSQL...")
+		}
+	}
+}
+
+func runInProcess(ctx context.Context, name, dsn string, cfg *config.DatabaseConfig) (*bench.Result, error) {
+	d, err := bench.New(name)
+	if err != nil {
+		return nil, err
+	}
+	res, err := bench.Run(ctx, d, dsn, cfg, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	bench.PrintSummary(os.Stdout, res)
+	return res, nil
+}
+
+// runBinary runs a prebuilt cmd/* binary and reads back the result it
+// writes with -json. A variant such as gorm-pq that has no binary of its
+// own is run by the binary of its base driver, here bin/gorm -driver=gorm-pq.
+func runBinary(ctx context.Context, binDir, name string, cfg *config.DatabaseConfig) (*bench.Result, error) {
+	path := filepath.Join(binDir, name)
+	if _, err := os.Stat(path); err != nil {
+		base, _, _ := strings.Cut(name, "-")
+		path = filepath.Join(binDir, base)
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("%w (build the binaries with: go build -o %s/ ./cmd/...)", err, binDir)
+		}
+	}
+
+	out, err := os.CreateTemp("", "bench-*.json")
+	if err != nil {
+		return nil, err
+	}
+	out.Close()
+	defer os.Remove(out.Name())
+
+	args := append([]string{"-driver", name, "-json", out.Name()}, config.Args(cfg)...)
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	results, err := bench.ReadResults(out.Name())
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("%s wrote %d results, want 1", path, len(results))
+	}
+	return results[0], nil
+}
@@ -0,0 +1,98 @@
+// Command report renders the results written by bench -json, either as the
+// Markdown table shown in the README or as a self-contained HTML page with
+// charts. Every value is the median over all runs of a driver.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"go-postgresql/bench"
+)
+
+// Markers delimiting the generated section of the README.
+const (
+	startMarker = "<!-- bench-results:start -->"
+	endMarker   = "<!-- bench-results:end -->"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format: markdown or html")
+	output := flag.String("o", "", "write the report to this file instead of stdout")
+	spread := flag.Bool("spread", false, "append ± half the min-max range to every median (markdown)")
+	readme := flag.String("readme", "", "rewrite the generated section of this Markdown file instead of printing (markdown)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: report [flags] result.json...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var results []*bench.Result
+	for _, path := range flag.Args() {
+		rs, err := bench.ReadResults(path)
+		if err != nil {
+			log.Fatalf("Failed to read results: %v", err)
+		}
+		results = append(results, rs...)
+	}
+
+	var report bytes.Buffer
+	switch *format {
+	case "markdown":
+		bench.WriteMarkdown(&report, bench.Summarize(results), *spread)
+	case "html":
+		if *readme != "" {
+			log.Fatal("-readme can only be used with -format=markdown")
+		}
+		if err := bench.WriteHTML(&report, results); err != nil {
+			log.Fatalf("Failed to render HTML report: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown format %q (want markdown or html)", *format)
+	}
+
+	switch {
+	case *readme != "":
+		if err := rewriteSection(*readme, report.String()); err != nil {
+			log.Fatalf("Failed to update %s: %v", *readme, err)
+		}
+	case *output != "":
+		if err := os.WriteFile(*output, report.Bytes(), 0o644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+	default:
+		os.Stdout.Write(report.Bytes())
+	}
+}
+
+// rewriteSection replaces everything between the start and end markers of
+// the file at path with content.
+func rewriteSection(path, content string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+
+	start := strings.Index(text, startMarker)
+	end := strings.Index(text, endMarker)
+	if start < 0 || end < start {
+		return fmt.Errorf("markers %s and %s not found", startMarker, endMarker)
+	}
+
+	var b strings.Builder
+	b.WriteString(text[:start+len(startMarker)])
+	b.WriteString("\n")
+	b.WriteString(content)
+	b.WriteString(text[end:])
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
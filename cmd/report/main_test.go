@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRewriteSection(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "empty section",
+			in:      "# Title\n" + startMarker + "\n" + endMarker + "\nfooter\n",
+			content: "| a |\n",
+			want:    "# Title\n" + startMarker + "\n| a |\n" + endMarker + "\nfooter\n",
+		},
+		{
+			name:    "replaces the old results",
+			in:      "head\n" + startMarker + "\nold\nresults\n" + endMarker + "\n",
+			content: "new\n",
+			want:    "head\n" + startMarker + "\nnew\n" + endMarker + "\n",
+		},
+		{name: "no markers", in: "# Title\n", content: "x\n", wantErr: true},
+		{name: "no end marker", in: startMarker + "\n", content: "x\n", wantErr: true},
+		{name: "markers swapped", in: endMarker + "\n" + startMarker + "\n", content: "x\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "README.md")
+			if err := os.WriteFile(path, []byte(tt.in), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := rewriteSection(path, tt.content)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("rewriteSection succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("rewriteSection wrote %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
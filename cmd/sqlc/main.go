@@ -0,0 +1,11 @@
+package main
+
+import (
+	"go-postgresql/bench"
+
+	_ "go-postgresql/driver/sqlcdriver"
+)
+
+func main() {
+	bench.Main("sqlc")
+}
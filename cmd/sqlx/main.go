@@ -0,0 +1,11 @@
+package main
+
+import (
+	"go-postgresql/bench"
+
+	_ "go-postgresql/driver/sqlxdriver"
+)
+
+func main() {
+	bench.Main("sqlx")
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"go-postgresql/bench"
+
+	_ "go-postgresql/driver/squirreldriver"
+)
+
+func main() {
+	bench.Main("squirrel")
+}
@@ -1,5 +1,17 @@
 package config
 
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDSN points at the PostgreSQL container from docker-compose.yml.
+const defaultDSN = "host=127.0.0.1 user=user password=password dbname=go_database port=5432 sslmode=disable"
+
 // DatabaseConfig holds database performance test configuration
 type DatabaseConfig struct {
 	InitialUsersCount int // 初期データ数
@@ -7,6 +19,63 @@ type DatabaseConfig struct {
 	UpdateCount       int // 更新対象数
 	DeleteCount       int // 削除対象数
 	NewUsersCount     int // 新規作成数
+
+	UpsertCount           int // アップサート対象数（0なら実行しない）
+	UpsertConflictPercent int // アップサートのうち既存メールアドレスの割合（%）
+
+	// セーブポイントの設定（SavepointCountが0なら実行しない）
+	SavepointCount           int // ネストしたトランザクションで挿入するユーザー数
+	SavepointRollbackPercent int // 内側でロールバックする割合（%）
+
+	// 競合ワークロードの設定（ContentionClientsが0なら実行しない）
+	ContentionClients      int           // 同時クライアント数
+	ContentionTransactions int           // クライアントあたりのトランザクション数
+	ContentionRows         int           // トランザクションあたりの更新行数
+	ContentionHotRows      int           // 更新対象となるIDの範囲（1〜この値）
+	RetryMaxAttempts       int           // 最大試行回数（初回を含む）
+	RetryBackoff           time.Duration // 初回リトライまでの待機時間（以降は倍増）
+	RetryMaxBackoff        time.Duration // 待機時間の上限
+
+	// スキーマ記述ファイルの設定（SchemaFileが空なら実行しない）
+	SchemaFile      string // テーブル定義を記述したJSONファイル
+	SchemaRowsCount int    // 生成して挿入する行数
+
+	// JSONBの設定（JsonbCountが0なら実行しない）
+	JsonbCount      int // プロフィール付きで挿入するユーザー数
+	JsonbQueryCount int // @>による検索の回数
+
+	TypesCount int // 型マッピングのフェーズで往復させる行数（0なら実行しない）
+
+	// バイナリデータの設定（BlobSizeが0なら実行しない）
+	BlobSize  int // 1件あたりのバイト数
+	BlobCount int // 書き込んで読み戻す件数
+
+	// リレーションの設定（RelationsUsersが0なら実行しない）
+	RelationsUsers  int // 投稿・コメントと一緒に読み込むユーザー数
+	PostsPerUser    int // ユーザーあたりの投稿数
+	CommentsPerPost int // 投稿あたりのコメント数
+
+	KeyStrategy string // usersの主キーの方式（KeyStrategiesのいずれか）
+
+	// トランザクションの設定（全ドライバー共通）
+	TxScope   string // none・batch・phase（TxScopesのいずれか）
+	Isolation string // 分離レベル（IsolationLevelsのいずれか）
+
+	// pgxpoolドライバーの設定
+	MaxConns          int           // 最大接続数
+	MinConns          int           // 最小接続数
+	HealthCheckPeriod time.Duration // ヘルスチェック間隔
+
+	// pgx・pgxpoolドライバーの設定
+	PgxQueryExecMode          string // pgx.QueryExecMode（QueryExecModesのいずれか）
+	PgxStatementCacheCapacity int    // StatementCacheCapacity
+
+	// GORMドライバーの設定（gorm.Config）
+	GormPrepareStmt            bool // PrepareStmt
+	GormSkipDefaultTransaction bool // SkipDefaultTransaction
+	GormCreateBatchSize        int  // CreateBatchSize（0は無効）
+	GormSilentLogger           bool // logger.Silent
+	GormQueryFields            bool // QueryFields
 }
 
 // DefaultConfig returns the default configuration for performance tests
@@ -17,6 +86,47 @@ func DefaultConfig() *DatabaseConfig {
 		UpdateCount:       5000,  // 更新対象数
 		DeleteCount:       2500,  // 削除対象数
 		NewUsersCount:     10000, // 新規作成数
+
+		UpsertCount:           0,  // アップサートのフェーズは無効
+		UpsertConflictPercent: 50, // 既存メールアドレスの割合（%）
+
+		SavepointCount:           0,  // セーブポイントのフェーズは無効
+		SavepointRollbackPercent: 20, // 内側でロールバックする割合（%）
+
+		ContentionClients:      0,                      // 競合ワークロードは無効
+		ContentionTransactions: 100,                    // クライアントあたりのトランザクション数
+		ContentionRows:         5,                      // トランザクションあたりの更新行数
+		ContentionHotRows:      50,                     // 更新対象となるIDの範囲
+		RetryMaxAttempts:       10,                     // 最大試行回数
+		RetryBackoff:           time.Millisecond,       // 初回リトライまでの待機時間
+		RetryMaxBackoff:        100 * time.Millisecond, // 待機時間の上限
+
+		SchemaFile:      "",    // スキーマ挿入のフェーズは無効
+		SchemaRowsCount: 10000, // 生成して挿入する行数
+
+		JsonbCount:      0,   // JSONBのフェーズは無効
+		JsonbQueryCount: 100, // @>による検索の回数
+
+		TypesCount: 0, // 型マッピングのフェーズは無効
+
+		BlobSize:  0,  // バイナリデータのフェーズは無効
+		BlobCount: 20, // 書き込んで読み戻す件数
+
+		RelationsUsers:  0, // リレーションのフェーズは無効
+		PostsPerUser:    5, // ユーザーあたりの投稿数
+		CommentsPerPost: 3, // 投稿あたりのコメント数
+
+		KeyStrategy: KeySerial, // init/init.sqlと同じSERIAL
+
+		TxScope:   TxScopeNone,      // 各ライブラリのデフォルト動作
+		Isolation: "read-committed", // PostgreSQLのデフォルト
+
+		MaxConns:          4,           // 最大接続数
+		MinConns:          0,           // 最小接続数
+		HealthCheckPeriod: time.Minute, // ヘルスチェック間隔
+
+		PgxQueryExecMode:          "cache_statement", // pgxのデフォルト
+		PgxStatementCacheCapacity: 512,               // pgxのデフォルト
 	}
 }
 
@@ -25,3 +135,238 @@ func DefaultConfig() *DatabaseConfig {
 func GetConfig() *DatabaseConfig {
 	return DefaultConfig()
 }
+
+// DSN returns the connection string of the benchmark database.
+// DATABASE_URL が設定されていればそちらを優先する
+func DSN() string {
+	if dsn, ok := LookupDSN(); ok {
+		return dsn
+	}
+	return defaultDSN
+}
+
+// LookupDSN returns DATABASE_URL and whether it is set. Unlike DSN it does
+// not fall back to the docker-compose default, so the go test benchmarks
+// can skip when no database has been configured.
+func LookupDSN() (string, bool) {
+	dsn := os.Getenv("DATABASE_URL")
+	return dsn, dsn != ""
+}
+
+// Field describes a DatabaseConfig value that can be set from the command
+// line, e.g. -batch-size=1000.
+type Field struct {
+	Flag  string // コマンドラインのフラグ名
+	Name  string // DatabaseConfigのフィールド名
+	Usage string
+	Ptr   func(*DatabaseConfig) *int
+}
+
+// Fields lists every DatabaseConfig value that can be set or swept.
+var Fields = []Field{
+	{"initial-users-count", "InitialUsersCount", "number of users seeded initially", func(c *DatabaseConfig) *int { return &c.InitialUsersCount }},
+	{"batch-size", "BatchSize", "rows per insert batch", func(c *DatabaseConfig) *int { return &c.BatchSize }},
+	{"update-count", "UpdateCount", "number of users updated", func(c *DatabaseConfig) *int { return &c.UpdateCount }},
+	{"delete-count", "DeleteCount", "number of users deleted", func(c *DatabaseConfig) *int { return &c.DeleteCount }},
+	{"new-users-count", "NewUsersCount", "number of users created after the bulk operations", func(c *DatabaseConfig) *int { return &c.NewUsersCount }},
+	{"upsert-count", "UpsertCount", "number of users upserted (0 disables the Upsert phase)", func(c *DatabaseConfig) *int { return &c.UpsertCount }},
+	{"upsert-conflict-percent", "UpsertConflictPercent", "percentage of upserted users whose email already exists", func(c *DatabaseConfig) *int { return &c.UpsertConflictPercent }},
+	{"savepoint-count", "SavepointCount", "users inserted in nested transactions (0 disables the Savepoint phase)", func(c *DatabaseConfig) *int { return &c.SavepointCount }},
+	{"savepoint-rollback-percent", "SavepointRollbackPercent", "percentage of nested transactions rolled back", func(c *DatabaseConfig) *int { return &c.SavepointRollbackPercent }},
+	{"contention-clients", "ContentionClients", "concurrent clients of the contention workload (0 disables it)", func(c *DatabaseConfig) *int { return &c.ContentionClients }},
+	{"contention-transactions", "ContentionTransactions", "transactions per contention client", func(c *DatabaseConfig) *int { return &c.ContentionTransactions }},
+	{"contention-rows", "ContentionRows", "rows updated per contention transaction", func(c *DatabaseConfig) *int { return &c.ContentionRows }},
+	{"contention-hot-rows", "ContentionHotRows", "contention transactions update ids from 1 to this value", func(c *DatabaseConfig) *int { return &c.ContentionHotRows }},
+	{"retry-max-attempts", "RetryMaxAttempts", "attempts per contention transaction, including the first", func(c *DatabaseConfig) *int { return &c.RetryMaxAttempts }},
+	{"schema-rows-count", "SchemaRowsCount", "rows generated for the -schema table", func(c *DatabaseConfig) *int { return &c.SchemaRowsCount }},
+	{"jsonb-count", "JsonbCount", "users inserted with a jsonb profile (0 disables the JSONB phases)", func(c *DatabaseConfig) *int { return &c.JsonbCount }},
+	{"jsonb-query-count", "JsonbQueryCount", "@> containment queries of the JSONB Query phase", func(c *DatabaseConfig) *int { return &c.JsonbQueryCount }},
+	{"types-count", "TypesCount", "rows round-tripped through type_samples (0 disables the Types phases)", func(c *DatabaseConfig) *int { return &c.TypesCount }},
+	{"blob-size", "BlobSize", "bytes per blob of the Bytea and Large Object phases, 1024 to 52428800 (0 disables them)", func(c *DatabaseConfig) *int { return &c.BlobSize }},
+	{"blob-count", "BlobCount", "blobs written and read back per phase", func(c *DatabaseConfig) *int { return &c.BlobCount }},
+	{"relations-users", "RelationsUsers", "users loaded with their posts and comments (0 disables the Relations phases)", func(c *DatabaseConfig) *int { return &c.RelationsUsers }},
+	{"posts-per-user", "PostsPerUser", "posts seeded for every user by the Relations Seed phase", func(c *DatabaseConfig) *int { return &c.PostsPerUser }},
+	{"comments-per-post", "CommentsPerPost", "comments seeded for every post by the Relations Seed phase", func(c *DatabaseConfig) *int { return &c.CommentsPerPost }},
+	{"max-conns", "MaxConns", "maximum connections of the pgxpool driver", func(c *DatabaseConfig) *int { return &c.MaxConns }},
+	{"min-conns", "MinConns", "minimum connections of the pgxpool driver", func(c *DatabaseConfig) *int { return &c.MinConns }},
+	{"pgx-statement-cache-capacity", "PgxStatementCacheCapacity", "statement cache capacity of the pgx drivers", func(c *DatabaseConfig) *int { return &c.PgxStatementCacheCapacity }},
+}
+
+// Range of DatabaseConfig.BlobSize when the Bytea and Large Object phases
+// are enabled.
+const (
+	MinBlobSize = 1 << 10  // 1KB
+	MaxBlobSize = 50 << 20 // 50MB
+)
+
+// Validate checks the settings whose range the phases rely on: no count
+// may be negative, and the batches need at least one row.
+func (c *DatabaseConfig) Validate() error {
+	for _, f := range Fields {
+		if v := *f.Ptr(c); v < 0 {
+			return fmt.Errorf("-%s=%d must not be negative", f.Flag, v)
+		}
+	}
+	if c.BatchSize < 1 {
+		return fmt.Errorf("-batch-size=%d must be at least 1", c.BatchSize)
+	}
+	if c.GormCreateBatchSize < 0 {
+		return fmt.Errorf("-gorm-create-batch-size=%d must not be negative", c.GormCreateBatchSize)
+	}
+	if c.BlobSize != 0 && (c.BlobSize < MinBlobSize || c.BlobSize > MaxBlobSize) {
+		return fmt.Errorf("-blob-size=%d is out of range: use 0 to disable or %d to %d bytes", c.BlobSize, MinBlobSize, MaxBlobSize)
+	}
+	return nil
+}
+
+// Primary key strategies of DatabaseConfig.KeyStrategy.
+const (
+	KeySerial        = "serial"          // SERIAL（init/init.sqlと同じ）
+	KeyIdentity      = "identity"        // BIGINT GENERATED BY DEFAULT AS IDENTITY
+	KeyUUIDv4        = "uuidv4"          // クライアントで生成するランダムなUUID
+	KeyUUIDv7        = "uuidv7"          // クライアントで生成する時刻順のUUID
+	KeyGenRandomUUID = "gen_random_uuid" // サーバー側のgen_random_uuid()
+)
+
+// KeyStrategies lists the values accepted by -key-strategy.
+var KeyStrategies = []string{KeySerial, KeyIdentity, KeyUUIDv4, KeyUUIDv7, KeyGenRandomUUID}
+
+// Transaction scopes of DatabaseConfig.TxScope.
+const (
+	TxScopeNone  = "none"  // 各ライブラリのデフォルト（pgx・pqは自動コミット、GORMはCreateごと）
+	TxScopeBatch = "batch" // 挿入バッチ・更新・削除ごとに1トランザクション
+	TxScopePhase = "phase" // 書き込みフェーズごとに1トランザクション
+)
+
+// TxScopes lists the values accepted by -tx-scope.
+var TxScopes = []string{TxScopeNone, TxScopeBatch, TxScopePhase}
+
+// IsolationLevels lists the values accepted by -isolation.
+var IsolationLevels = []string{"read-committed", "repeatable-read", "serializable"}
+
+// QueryExecModes lists the names of the pgx.QueryExecMode values accepted
+// by -pgx-exec-mode, in the order pgx defines them.
+var QueryExecModes = []string{"cache_statement", "cache_describe", "describe_exec", "exec", "simple_protocol"}
+
+// RegisterFlags defines one flag per setting on fs, writing into cfg.
+func RegisterFlags(fs *flag.FlagSet, cfg *DatabaseConfig) {
+	for _, f := range Fields {
+		fs.IntVar(f.Ptr(cfg), f.Flag, *f.Ptr(cfg), f.Usage)
+	}
+	RegisterOptionFlags(fs, cfg)
+}
+
+// RegisterOptionFlags defines the flags of the settings that are not in
+// Fields and therefore cannot be swept.
+func RegisterOptionFlags(fs *flag.FlagSet, cfg *DatabaseConfig) {
+	fs.StringVar(&cfg.KeyStrategy, "key-strategy", cfg.KeyStrategy, "primary key of the users table, one of: "+strings.Join(KeyStrategies, ","))
+	fs.StringVar(&cfg.TxScope, "tx-scope", cfg.TxScope, "transaction scope of the write phases, one of: "+strings.Join(TxScopes, ","))
+	fs.StringVar(&cfg.Isolation, "isolation", cfg.Isolation, "isolation level of the -tx-scope transactions, one of: "+strings.Join(IsolationLevels, ","))
+
+	fs.StringVar(&cfg.SchemaFile, "schema", cfg.SchemaFile, "JSON file describing the table of the Schema Insert phase (empty disables it)")
+
+	fs.DurationVar(&cfg.HealthCheckPeriod, "health-check-period", cfg.HealthCheckPeriod, "health check period of the pgxpool driver")
+	fs.DurationVar(&cfg.RetryBackoff, "retry-backoff", cfg.RetryBackoff, "wait before the first retry of a contention transaction, doubled on every further retry")
+	fs.DurationVar(&cfg.RetryMaxBackoff, "retry-max-backoff", cfg.RetryMaxBackoff, "upper bound of the contention retry wait")
+	fs.StringVar(&cfg.PgxQueryExecMode, "pgx-exec-mode", cfg.PgxQueryExecMode, "query exec mode of the pgx drivers, one of: "+strings.Join(QueryExecModes, ","))
+
+	fs.BoolVar(&cfg.GormPrepareStmt, "gorm-prepare-stmt", cfg.GormPrepareStmt, "enable gorm.Config.PrepareStmt")
+	fs.BoolVar(&cfg.GormSkipDefaultTransaction, "gorm-skip-default-tx", cfg.GormSkipDefaultTransaction, "enable gorm.Config.SkipDefaultTransaction")
+	fs.IntVar(&cfg.GormCreateBatchSize, "gorm-create-batch-size", cfg.GormCreateBatchSize, "gorm.Config.CreateBatchSize (0 disables)")
+	fs.BoolVar(&cfg.GormSilentLogger, "gorm-silent", cfg.GormSilentLogger, "use logger.Silent instead of GORM's default logger")
+	fs.BoolVar(&cfg.GormQueryFields, "gorm-query-fields", cfg.GormQueryFields, "enable gorm.Config.QueryFields")
+}
+
+// Args returns the command line flags that reproduce cfg.
+func Args(cfg *DatabaseConfig) []string {
+	c := *cfg
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	RegisterFlags(fs, &c)
+
+	var args []string
+	fs.VisitAll(func(f *flag.Flag) {
+		args = append(args, fmt.Sprintf("-%s=%s", f.Name, f.Value))
+	})
+	return args
+}
+
+// ParseValues parses a sweep value: a comma separated list of integers and
+// ranges written as start..end:step, e.g. "100,500,1000..5000:1000".
+func ParseValues(s string) ([]int, error) {
+	var values []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		from, rest, isRange := strings.Cut(part, "..")
+		if !isRange {
+			v, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value %q", part)
+			}
+			values = append(values, v)
+			continue
+		}
+
+		to, stepStr, hasStep := strings.Cut(rest, ":")
+		if !hasStep {
+			return nil, fmt.Errorf("range %q needs a step, e.g. %s:1000", part, part)
+		}
+		start, err1 := strconv.Atoi(from)
+		end, err2 := strconv.Atoi(to)
+		step, err3 := strconv.Atoi(stepStr)
+		if err1 != nil || err2 != nil || err3 != nil || step <= 0 || end < start {
+			return nil, fmt.Errorf("invalid range %q", part)
+		}
+		for v := start; v <= end; v += step {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// Sweep returns the Cartesian product of the given values applied to base.
+// values is keyed by Field.Flag; fields without values keep their base value.
+// The last field in Fields varies fastest.
+func Sweep(base DatabaseConfig, values map[string][]int) []DatabaseConfig {
+	configs := []DatabaseConfig{base}
+	for _, f := range Fields {
+		vs := values[f.Flag]
+		if len(vs) == 0 {
+			continue
+		}
+		next := make([]DatabaseConfig, 0, len(configs)*len(vs))
+		for _, c := range configs {
+			for _, v := range vs {
+				*f.Ptr(&c) = v
+				next = append(next, c)
+			}
+		}
+		configs = next
+	}
+	return configs
+}
+
+// GormMatrix returns a copy of base for every combination of the GORM
+// tuning options: PrepareStmt, SkipDefaultTransaction, logger.Silent and
+// QueryFields each off and on, and CreateBatchSize off and set to
+// base.GormCreateBatchSize, or 1000 when that is 0.
+func GormMatrix(base DatabaseConfig) []DatabaseConfig {
+	batchSize := base.GormCreateBatchSize
+	if batchSize == 0 {
+		batchSize = 1000
+	}
+
+	var configs []DatabaseConfig
+	for i := 0; i < 1<<5; i++ {
+		c := base
+		c.GormPrepareStmt = i&1 != 0
+		c.GormSkipDefaultTransaction = i&2 != 0
+		c.GormSilentLogger = i&4 != 0
+		c.GormQueryFields = i&8 != 0
+		c.GormCreateBatchSize = 0
+		if i&16 != 0 {
+			c.GormCreateBatchSize = batchSize
+		}
+		configs = append(configs, c)
+	}
+	return configs
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{in: "1000", want: []int{1000}},
+		{in: "100, 500,1000", want: []int{100, 500, 1000}},
+		{in: "1000..5000:1000", want: []int{1000, 2000, 3000, 4000, 5000}},
+		{in: "1..10:4", want: []int{1, 5, 9}},
+		{in: "100,1000..3000:1000", want: []int{100, 1000, 2000, 3000}},
+		{in: "5..5:1", want: []int{5}},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+		{in: "1000..5000", wantErr: true},
+		{in: "1000..5000:0", wantErr: true},
+		{in: "5000..1000:1000", wantErr: true},
+		{in: "1..x:1", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseValues(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseValues(%q) = %v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseValues(%q) returned %v", tt.in, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseValues(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSweep(t *testing.T) {
+	base := DatabaseConfig{BatchSize: 5000, UpdateCount: 10}
+	tests := []struct {
+		name   string
+		values map[string][]int
+		want   [][2]int // {BatchSize, UpdateCount}
+	}{
+		{name: "no values", values: nil, want: [][2]int{{5000, 10}}},
+		{name: "one field", values: map[string][]int{"batch-size": {100, 1000}}, want: [][2]int{{100, 10}, {1000, 10}}},
+		{
+			name:   "last field varies fastest",
+			values: map[string][]int{"update-count": {1, 2}, "batch-size": {100, 1000}},
+			want:   [][2]int{{100, 1}, {100, 2}, {1000, 1}, {1000, 2}},
+		},
+		{name: "unknown flag", values: map[string][]int{"no-such-flag": {1, 2}}, want: [][2]int{{5000, 10}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configs := Sweep(base, tt.values)
+			var got [][2]int
+			for _, c := range configs {
+				got = append(got, [2]int{c.BatchSize, c.UpdateCount})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sweep = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGormMatrix(t *testing.T) {
+	tests := []struct {
+		name          string
+		batchSize     int
+		wantBatchSize int
+	}{
+		{name: "default batch size", batchSize: 0, wantBatchSize: 1000},
+		{name: "base batch size", batchSize: 250, wantBatchSize: 250},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := DatabaseConfig{BatchSize: 5000, GormCreateBatchSize: tt.batchSize}
+			configs := GormMatrix(base)
+			if len(configs) != 32 {
+				t.Fatalf("GormMatrix returned %d configs, want 32", len(configs))
+			}
+
+			type options struct {
+				prepare, skipTx, silent, queryFields bool
+				batchSize                            int
+			}
+			seen := map[options]bool{}
+			for _, c := range configs {
+				if c.BatchSize != base.BatchSize {
+					t.Errorf("BatchSize = %d, want %d", c.BatchSize, base.BatchSize)
+				}
+				if c.GormCreateBatchSize != 0 && c.GormCreateBatchSize != tt.wantBatchSize {
+					t.Errorf("GormCreateBatchSize = %d, want 0 or %d", c.GormCreateBatchSize, tt.wantBatchSize)
+				}
+				seen[options{c.GormPrepareStmt, c.GormSkipDefaultTransaction, c.GormSilentLogger, c.GormQueryFields, c.GormCreateBatchSize}] = true
+			}
+			if len(seen) != 32 {
+				t.Errorf("GormMatrix returned %d distinct combinations, want 32", len(seen))
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     func(c *DatabaseConfig)
+		wantErr bool
+	}{
+		{name: "defaults", set: func(c *DatabaseConfig) {}},
+		{name: "blob size disabled", set: func(c *DatabaseConfig) { c.BlobSize = 0 }},
+		{name: "smallest blob", set: func(c *DatabaseConfig) { c.BlobSize = MinBlobSize }},
+		{name: "largest blob", set: func(c *DatabaseConfig) { c.BlobSize = MaxBlobSize }},
+		{name: "blob of 64KB", set: func(c *DatabaseConfig) { c.BlobSize = 64 << 10 }},
+		{name: "blob too small", set: func(c *DatabaseConfig) { c.BlobSize = MinBlobSize - 1 }, wantErr: true},
+		{name: "blob too large", set: func(c *DatabaseConfig) { c.BlobSize = MaxBlobSize + 1 }, wantErr: true},
+		{name: "negative blob size", set: func(c *DatabaseConfig) { c.BlobSize = -1 }, wantErr: true},
+		{name: "batch of one", set: func(c *DatabaseConfig) { c.BatchSize = 1 }},
+		{name: "zero batch size", set: func(c *DatabaseConfig) { c.BatchSize = 0 }, wantErr: true},
+		{name: "negative batch size", set: func(c *DatabaseConfig) { c.BatchSize = -5000 }, wantErr: true},
+		{name: "zero counts", set: func(c *DatabaseConfig) { c.InitialUsersCount, c.UpdateCount, c.NewUsersCount = 0, 0, 0 }},
+		{name: "negative count", set: func(c *DatabaseConfig) { c.DeleteCount = -1 }, wantErr: true},
+		{name: "negative percent", set: func(c *DatabaseConfig) { c.UpsertConflictPercent = -10 }, wantErr: true},
+		{name: "negative gorm batch size", set: func(c *DatabaseConfig) { c.GormCreateBatchSize = -1 }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := DefaultConfig()
+			tt.set(c)
+			if err := c.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate returned %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSweep(t *testing.T) {
+	values, err := ParseValues("1000,0,5000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var invalid []int
+	for _, c := range Sweep(*DefaultConfig(), map[string][]int{"batch-size": values}) {
+		if c.Validate() != nil {
+			invalid = append(invalid, c.BatchSize)
+		}
+	}
+	if !reflect.DeepEqual(invalid, []int{0}) {
+		t.Errorf("Validate rejected the batch sizes %v of the sweep, want [0]", invalid)
+	}
+}
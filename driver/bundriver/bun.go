@@ -0,0 +1,214 @@
+// Package bundriver implements the benchmark phases with the Bun ORM and
+// its PostgreSQL dialect, using Bun's bulk APIs: NewInsert with a slice,
+// NewUpdate().Bulk() and NewDelete with bun.In.
+//
+// Two variants are registered, differing only in the *sql.DB underneath:
+//
+//	bun            Bun's own pgdriver
+//	bun-pgxstdlib  pgx's stdlib package
+package bundriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/driver/pgdriver"
+)
+
+func init() {
+	bench.Register("bun", New)
+	bench.Register("bun-pgxstdlib", func() bench.Driver {
+		return &Driver{pgxStdlib: true, driverName: "BUN/PGX-STDLIB"}
+	})
+}
+
+// User corresponds to the users table in the database.
+type User struct {
+	bun.BaseModel `bun:"table:users"`
+
+	ID        int64     `bun:"id,pk,autoincrement"`
+	Name      string    `bun:"name"`
+	Email     string    `bun:"email"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+// uuidUser is User for the uuid key strategies. Bulk() casts the ids in its
+// VALUES list to the SQL type of the primary key, so the uuid key needs a
+// model of its own; nullzero inserts DEFAULT for the ids left to the server.
+type uuidUser struct {
+	bun.BaseModel `bun:"table:users"`
+
+	ID        string    `bun:"id,pk,type:uuid,nullzero"`
+	Name      string    `bun:"name"`
+	Email     string    `bun:"email"`
+	CreatedAt time.Time `bun:"created_at"`
+}
+
+// Driver runs the phases through a *bun.DB, or the bun.Tx of InTx.
+type Driver struct {
+	db         *bun.DB
+	idb        bun.IDB
+	pgxStdlib  bool
+	uuidKeys   bool // users.idがuuid（uuidUserモデルを使う）
+	driverName string
+}
+
+// New returns an unopened Bun driver over pgdriver.
+func New() bench.Driver {
+	return &Driver{driverName: "BUN"}
+}
+
+func (d *Driver) Name() string { return d.driverName }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	sqlDB, err := d.openDB(dsn)
+	if err != nil {
+		return err
+	}
+	db := bun.NewDB(sqlDB, pgdialect.New())
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	d.db = db
+	d.idb = db
+	return nil
+}
+
+// openDB opens the *sql.DB of the configured variant. pgdriver only accepts
+// URL connection strings, so the DSN is parsed with pgx and handed over as
+// options, which also covers the key=value form of the default DSN.
+func (d *Driver) openDB(dsn string) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if d.pgxStdlib {
+		return stdlib.OpenDB(*connConfig), nil
+	}
+
+	opts := []pgdriver.Option{
+		pgdriver.WithAddr(fmt.Sprintf("%s:%d", connConfig.Host, connConfig.Port)),
+		pgdriver.WithUser(connConfig.User),
+		pgdriver.WithPassword(connConfig.Password),
+		pgdriver.WithDatabase(connConfig.Database),
+	}
+	if connConfig.TLSConfig == nil {
+		opts = append(opts, pgdriver.WithInsecure(true))
+	} else {
+		opts = append(opts, pgdriver.WithTLSConfig(connConfig.TLSConfig))
+	}
+	return sql.OpenDB(pgdriver.NewConnector(opts...)), nil
+}
+
+func (d *Driver) Close() error {
+	return d.db.Close()
+}
+
+// InTx runs fn inside db.RunInTx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	defer func() { d.idb = d.db }()
+	return d.db.RunInTx(ctx, opts, func(ctx context.Context, tx bun.Tx) error {
+		d.idb = tx
+		return fn(ctx)
+	})
+}
+
+// Reset issues TRUNCATE TABLE users RESTART IDENTITY; the pg dialect adds
+// RESTART IDENTITY unless ContinueIdentity is set.
+func (d *Driver) Reset(ctx context.Context) error {
+	_, err := d.idb.NewTruncateTable().Model((*User)(nil)).Exec(ctx)
+	return err
+}
+
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	if d.uuidKeys {
+		batchUsers := make([]uuidUser, 0, len(users))
+		for _, u := range users {
+			batchUsers = append(batchUsers, uuidUser{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt})
+		}
+		_, err := d.idb.NewInsert().Model(&batchUsers).Exec(ctx)
+		return err
+	}
+	batchUsers := make([]User, 0, len(users))
+	for _, u := range users {
+		batchUsers = append(batchUsers, User{Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt})
+	}
+	_, err := d.idb.NewInsert().Model(&batchUsers).Exec(ctx)
+	return err
+}
+
+// Contend renames the given users one NewUpdate at a time inside RunInTx.
+// *bun.DB hands each concurrent call its own connection.
+func (d *Driver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	return d.db.RunInTx(ctx, opts, func(ctx context.Context, tx bun.Tx) error {
+		for _, id := range ids {
+			_, err := tx.NewUpdate().Model((*User)(nil)).
+				Set("name = ?", fmt.Sprintf("Contended_User_%06d", id)).
+				Where("id = ?", id).
+				Exec(ctx)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	return d.idb.NewSelect().Model((*User)(nil)).Count(ctx)
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	if d.uuidKeys {
+		return updateNames(ctx, d.idb, n, func(u *uuidUser) { u.Name = "Updated_User_Bulk_BUN" })
+	}
+	return updateNames(ctx, d.idb, n, func(u *User) { u.Name = "Updated_User_Bulk_BUN" })
+}
+
+// updateNames renames the first n users of model M with a single Bulk()
+// update.
+func updateNames[M any](ctx context.Context, idb bun.IDB, n int, rename func(*M)) (int, error) {
+	// Get users to update
+	var users []M
+	if err := idb.NewSelect().Model(&users).Column("id").Limit(n).Scan(ctx); err != nil {
+		return 0, err
+	}
+
+	// Bulk update using a single statement
+	if len(users) > 0 {
+		for i := range users {
+			rename(&users[i])
+		}
+		if _, err := idb.NewUpdate().Model(&users).Column("name").Bulk().Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(users), nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	// Get IDs of users to delete, as strings to cover both key types
+	var deleteIDs []string
+	if err := d.idb.NewSelect().Model((*User)(nil)).Column("id").Offset(1000).Limit(n).Scan(ctx, &deleteIDs); err != nil {
+		return 0, err
+	}
+
+	// Bulk delete using a single statement
+	if len(deleteIDs) > 0 {
+		if _, err := d.idb.NewDelete().Model((*User)(nil)).Where("id IN (?)", bun.In(deleteIDs)).Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(deleteIDs), nil
+}
@@ -0,0 +1,161 @@
+// Package entdriver implements the benchmark phases with the ent entity
+// framework: CreateBulk for inserts and Update/Delete filtered with
+// user.IDIn for the bulk operations. The schema is in ent/schema and the
+// generated client in ent; regenerate it with go generate ./driver/entdriver/ent.
+// The schema types id as a userid.ID string, which covers every key strategy.
+package entdriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+	"go-postgresql/driver/entdriver/ent"
+	"go-postgresql/driver/entdriver/ent/schema/userid"
+	"go-postgresql/driver/entdriver/ent/user"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+func init() {
+	bench.Register("ent", New)
+}
+
+// Driver runs the phases through an *ent.Client over pgx's database/sql
+// driver, or the transactional client of InTx.
+type Driver struct {
+	db     *sql.DB
+	client *ent.Client
+}
+
+// New returns an unopened ent driver.
+func New() bench.Driver {
+	return &Driver{}
+}
+
+func (d *Driver) Name() string { return "ENT" }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return err
+	}
+	d.db = db
+	d.client = ent.NewClient(ent.Driver(entsql.OpenDB(dialect.Postgres, db)))
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.client.Close()
+}
+
+// InTx runs fn with the phases sent through the client of an *ent.Tx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := d.client.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	client := d.client
+	d.client = tx.Client()
+	defer func() { d.client = client }()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// Reset runs TRUNCATE directly, as ent has no builder for it.
+func (d *Driver) Reset(ctx context.Context) error {
+	_, err := d.db.ExecContext(ctx, "TRUNCATE TABLE users RESTART IDENTITY")
+	return err
+}
+
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	builders := make([]*ent.UserCreate, 0, len(users))
+	for _, u := range users {
+		create := d.client.User.Create().
+			SetName(u.Name).
+			SetEmail(u.Email).
+			SetCreatedAt(u.CreatedAt)
+		// クライアントで生成したIDのみ指定し、それ以外はDBのデフォルトに任せる
+		if u.ID != "" {
+			create.SetID(userid.ID(u.ID))
+		}
+		builders = append(builders, create)
+	}
+	// CreateBulkは1文で送るためパラメータ数の上限に収まるよう分割する
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)) {
+		if err := d.client.User.CreateBulk(builders[c[0]:c[1]]...).Exec(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Contend renames the given users one Update at a time in a transaction
+// of the client. The client's *sql.DB hands each concurrent call its own
+// connection.
+func (d *Driver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	tx, err := d.client.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		err := tx.User.Update().
+			Where(user.ID(userid.ID(strconv.Itoa(id)))).
+			SetName(fmt.Sprintf("Contended_User_%06d", id)).
+			Exec(ctx)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	return d.client.User.Query().Count(ctx)
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	// Get IDs of users to update
+	userIDs, err := d.client.User.Query().Limit(n).IDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Bulk update using a single statement
+	if len(userIDs) > 0 {
+		if err := d.client.User.Update().Where(user.IDIn(userIDs...)).SetName("Updated_User_Bulk_ENT").Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(userIDs), nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	// Get IDs of users to delete
+	deleteIDs, err := d.client.User.Query().Offset(1000).Limit(n).IDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Bulk delete using a single statement
+	if len(deleteIDs) > 0 {
+		if _, err := d.client.User.Delete().Where(user.IDIn(deleteIDs...)).Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+	return len(deleteIDs), nil
+}
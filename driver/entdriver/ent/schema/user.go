@@ -0,0 +1,27 @@
+package schema
+
+import (
+	"time"
+
+	"go-postgresql/driver/entdriver/ent/schema/userid"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+)
+
+// User holds the schema definition for the User entity. It matches the
+// users table in init/init.sql, except that id is a userid.ID so that the
+// uuid key strategies work as well.
+type User struct {
+	ent.Schema
+}
+
+// Fields of the User.
+func (User) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("id").GoType(userid.ID("")),
+		field.String("name").MaxLen(100),
+		field.String("email").MaxLen(100).Unique(),
+		field.Time("created_at").Optional().Default(time.Now).Immutable(),
+	}
+}
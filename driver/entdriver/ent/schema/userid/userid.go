@@ -0,0 +1,33 @@
+// Package userid defines the Go type of the User id in the ent schema.
+package userid
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// ID is users.id in its text form, so that one generated client covers
+// both the integer and the uuid key strategies. The database converts the
+// text back to the type of the column.
+type ID string
+
+// Scan implements sql.Scanner for the integer and uuid columns.
+func (id *ID) Scan(src any) error {
+	switch v := src.(type) {
+	case int64:
+		*id = ID(strconv.FormatInt(v, 10))
+	case string:
+		*id = ID(v)
+	case []byte:
+		*id = ID(v)
+	default:
+		return fmt.Errorf("userid: unsupported type %T", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer.
+func (id ID) Value() (driver.Value, error) {
+	return string(id), nil
+}
@@ -0,0 +1,649 @@
+// Package gormdriver implements the benchmark phases with the GORM ORM.
+//
+// Besides the default gorm.Open(postgres.Open(dsn)) setup, it registers
+// variants that hand GORM an existing *sql.DB through postgres.Config.Conn,
+// so that GORM's own overhead can be told apart from the driver underneath:
+//
+//	gorm-pgxstdlib         *sql.DB from pgx's stdlib package
+//	gorm-pgxstdlib-simple  the same with the simple query protocol
+//	gorm-pq                *sql.DB from lib/pq
+//
+// The gorm.Config tuning options (PrepareStmt, SkipDefaultTransaction,
+// CreateBatchSize, logger.Silent and QueryFields) are read from the
+// DatabaseConfig, and the enabled ones are appended to the driver name,
+// e.g. "GORM[prepare,skip-tx]", so each combination reports as its own
+// variant.
+package gormdriver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+func init() {
+	bench.Register("gorm", New)
+	bench.Register("gorm-pgxstdlib", func() bench.Driver {
+		return &Driver{conn: connPgxStdlib, driverName: "GORM/PGX-STDLIB"}
+	})
+	bench.Register("gorm-pgxstdlib-simple", func() bench.Driver {
+		return &Driver{conn: connPgxStdlib, simpleProtocol: true, driverName: "GORM/PGX-STDLIB-SIMPLE"}
+	})
+	bench.Register("gorm-pq", func() bench.Driver {
+		return &Driver{conn: connPQ, driverName: "GORM/PQ"}
+	})
+}
+
+// How the *sql.DB underneath GORM is created.
+const (
+	connDefault   = iota // gorm.Open(postgres.Open(dsn))
+	connPgxStdlib        // stdlib.OpenDB passed as postgres.Config.Conn
+	connPQ               // sql.Open("postgres") passed as postgres.Config.Conn
+)
+
+// User corresponds to the users table in the database. ID is a string so
+// that the model fits every key strategy: database/sql converts integer
+// ids, and the default tag makes GORM leave an empty ID to the database
+// and read it back with RETURNING.
+type User struct {
+	ID        string `gorm:"primaryKey;default:(-)"`
+	Name      string
+	Email     string `gorm:"unique"`
+	CreatedAt time.Time
+}
+
+// upsertUser is User plus the RETURNING (xmax = 0) flag of the Upsert
+// phase. The flag is read-only, so GORM never writes it.
+type upsertUser struct {
+	User
+	Inserted bool `gorm:"->"`
+}
+
+func (upsertUser) TableName() string { return "users" }
+
+// profileUser is User plus the profile jsonb column of the JSONB phases.
+type profileUser struct {
+	User
+	Profile jsonb[bench.Profile] `gorm:"type:jsonb"`
+}
+
+func (profileUser) TableName() string { return "users" }
+
+// jsonb stores Data as a JSON document through database/sql, like
+// gorm.io/datatypes.JSONType does, encoding it with the timed
+// bench.MarshalJSON and bench.UnmarshalJSON.
+type jsonb[T any] struct {
+	Data T
+}
+
+func (j jsonb[T]) Value() (driver.Value, error) {
+	data, err := bench.MarshalJSON(j.Data)
+	return string(data), err
+}
+
+func (j *jsonb[T]) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return bench.UnmarshalJSON(v, &j.Data)
+	case string:
+		return bench.UnmarshalJSON([]byte(v), &j.Data)
+	}
+	return fmt.Errorf("gormdriver: cannot scan %T into jsonb", value)
+}
+
+// author is User with the posts of the Relations phases.
+type author struct {
+	User
+	Posts []post `gorm:"foreignKey:UserID"`
+}
+
+func (author) TableName() string { return "users" }
+
+// post corresponds to the posts table. User is the belongs-to side that
+// LoadJoined fills through Joins.
+type post struct {
+	ID       int64
+	UserID   string
+	Title    string
+	Body     string
+	Comments []comment
+	User     *User
+}
+
+// comment corresponds to the comments table.
+type comment struct {
+	ID     int64
+	PostID int64
+	Body   string
+}
+
+// typeSample corresponds to the type_samples table of the Types phases.
+// The columns GORM has no Go type for are held as lib/pq's array types or
+// as their text form, which both database/sql drivers accept.
+type typeSample struct {
+	ID         int64
+	UID        string         `gorm:"column:uid"`
+	Amount     string         // numeric
+	IDs        pq.Int64Array  `gorm:"column:ids;type:int8[]"`
+	Labels     pq.StringArray `gorm:"type:text[]"`
+	Payload    []byte
+	Addr       string // inet
+	Span       string // interval
+	HappenedAt time.Time
+	Note       *string
+	Quantity   *int64
+}
+
+// blob corresponds to the blobs table of the Bytea phase.
+type blob struct {
+	ID   int64
+	Data []byte
+}
+
+// Driver runs the phases through a *gorm.DB.
+type Driver struct {
+	db             *gorm.DB
+	conn           int
+	simpleProtocol bool
+	driverName     string
+	options        []string     // 有効にしたgorm.Configのオプション（Nameに付記）
+	uuidKeys       bool         // users.idがuuid（文字列で受け渡す）
+	queries        atomic.Int64 // 送信したクエリの数（Relationsフェーズで使う）
+}
+
+// New returns an unopened GORM driver.
+func New() bench.Driver {
+	return &Driver{conn: connDefault, driverName: "GORM"}
+}
+
+// Name returns the driver label, followed by the tuning options enabled
+// in Open, if any.
+func (d *Driver) Name() string {
+	if len(d.options) == 0 {
+		return d.driverName
+	}
+	return d.driverName + "[" + strings.Join(d.options, ",") + "]"
+}
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	dialector, err := d.dialector(dsn)
+	if err != nil {
+		return err
+	}
+
+	// Open a connection to the database.
+	db, err := gorm.Open(dialector, d.gormConfig(cfg))
+	if err != nil {
+		return err
+	}
+	d.db = db
+	d.uuidKeys = bench.UUIDKeys(cfg)
+
+	// Count every query, including the ones Preload sends on its own, for
+	// the Relations phases.
+	return db.Callback().Query().After("gorm:query").Register("bench:count_queries", func(*gorm.DB) {
+		d.queries.Add(1)
+	})
+}
+
+// gormConfig builds the gorm.Config for the tuning options in cfg and
+// records the enabled ones for Name.
+func (d *Driver) gormConfig(cfg *config.DatabaseConfig) *gorm.Config {
+	gc := &gorm.Config{
+		PrepareStmt:            cfg.GormPrepareStmt,
+		SkipDefaultTransaction: cfg.GormSkipDefaultTransaction,
+		CreateBatchSize:        cfg.GormCreateBatchSize,
+		QueryFields:            cfg.GormQueryFields,
+	}
+
+	d.options = nil
+	if cfg.GormPrepareStmt {
+		d.options = append(d.options, "prepare")
+	}
+	if cfg.GormSkipDefaultTransaction {
+		d.options = append(d.options, "skip-tx")
+	}
+	if cfg.GormCreateBatchSize > 0 {
+		d.options = append(d.options, fmt.Sprintf("batch=%d", cfg.GormCreateBatchSize))
+	}
+	if cfg.GormSilentLogger {
+		gc.Logger = logger.Default.LogMode(logger.Silent)
+		d.options = append(d.options, "silent")
+	}
+	if cfg.GormQueryFields {
+		d.options = append(d.options, "query-fields")
+	}
+	return gc
+}
+
+// dialector builds the postgres dialector for the configured connection.
+// GORM ignores PreferSimpleProtocol when Conn is set, so the exec mode is
+// applied to the pgx config the *sql.DB is built from instead.
+func (d *Driver) dialector(dsn string) (gorm.Dialector, error) {
+	switch d.conn {
+	case connPgxStdlib:
+		connConfig, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if d.simpleProtocol {
+			connConfig.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+		}
+		sqlDB := stdlib.OpenDB(*connConfig)
+		return postgres.New(postgres.Config{Conn: sqlDB, PreferSimpleProtocol: d.simpleProtocol}), nil
+	case connPQ:
+		sqlDB, err := sql.Open("postgres", dsn)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.New(postgres.Config{Conn: sqlDB}), nil
+	default:
+		return postgres.Open(dsn), nil
+	}
+}
+
+// InTx runs fn inside db.Transaction. GORM skips its default per-Create
+// transaction when the statement already runs in one.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	db := d.db
+	defer func() { d.db = db }()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		d.db = tx
+		return fn(ctx)
+	}, opts)
+}
+
+func (d *Driver) Close() error {
+	sqlDB, err := d.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+func (d *Driver) Reset(ctx context.Context) error {
+	return d.db.WithContext(ctx).Exec("TRUNCATE TABLE users RESTART IDENTITY").Error
+}
+
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	batchUsers := make([]User, 0, len(users))
+	for _, u := range users {
+		batchUsers = append(batchUsers, User{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt})
+	}
+	return createChunks(d.db.WithContext(ctx), batchUsers, bench.UserParams(users))
+}
+
+// Upsert creates the batch with clause.OnConflict, renaming the existing
+// user on an email conflict, and reads RETURNING (xmax = 0) back into
+// upsertUser.Inserted.
+func (d *Driver) Upsert(ctx context.Context, users []bench.User) (inserted, updated int, err error) {
+	batchUsers := make([]upsertUser, 0, len(users))
+	for _, u := range users {
+		batchUsers = append(batchUsers, upsertUser{User: User{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt}})
+	}
+
+	// Sessionで句を保持したまま複数回Createできるようにする
+	db := d.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "email"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "id"}, {Name: "(xmax = 0) AS inserted", Raw: true}}},
+	).Session(&gorm.Session{})
+	if err := createChunks(db, batchUsers, bench.UserParams(users)); err != nil {
+		return 0, 0, err
+	}
+
+	for _, u := range batchUsers {
+		if u.Inserted {
+			inserted++
+		} else {
+			updated++
+		}
+	}
+	return inserted, updated, nil
+}
+
+// errRollback makes a nested db.Transaction roll back to its savepoint.
+var errRollback = errors.New("gormdriver: rollback nested transaction")
+
+// InsertNested creates every user in a nested db.Transaction, which GORM
+// implements with SavePoint and RollbackTo, and rolls back the flagged ones
+// by returning errRollback from the nested function.
+func (d *Driver) InsertNested(ctx context.Context, users []bench.User, rollback []bool) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, u := range users {
+			err := tx.Transaction(func(nested *gorm.DB) error {
+				if err := nested.Create(&User{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt}).Error; err != nil {
+					return err
+				}
+				if rollback[i] {
+					return errRollback
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errRollback) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExecSchema runs the DDL statements with db.Exec.
+func (d *Driver) ExecSchema(ctx context.Context, stmts []string) error {
+	for _, stmt := range stmts {
+		if err := d.db.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertRows creates the batch from one map per row, as there is no model
+// struct for a table only known at run time.
+func (d *Driver) InsertRows(ctx context.Context, s *bench.Schema, rows [][]any) error {
+	names := s.ColumnNames()
+	records := make([]map[string]any, len(rows))
+	for i, row := range rows {
+		record := make(map[string]any, len(names))
+		for j, name := range names {
+			record[name] = row[j]
+		}
+		records[i] = record
+	}
+	return d.db.WithContext(ctx).Table(s.Table).Create(&records).Error
+}
+
+func (d *Driver) InsertProfiles(ctx context.Context, users []bench.User, profiles []bench.Profile) error {
+	batchUsers := make([]profileUser, 0, len(users))
+	for i, u := range users {
+		batchUsers = append(batchUsers, profileUser{
+			User:    User{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt},
+			Profile: jsonb[bench.Profile]{Data: profiles[i]},
+		})
+	}
+	return createChunks(d.db.WithContext(ctx), batchUsers, bench.UserParams(users)+1)
+}
+
+// createChunks creates rows with one Create per bench.ParamChunks range, as
+// GORM sends a whole slice as one INSERT unless CreateBatchSize is set. db
+// must be safe to reuse, e.g. a Session.
+func createChunks[T any](db *gorm.DB, rows []T, params int) error {
+	for _, c := range bench.ParamChunks(len(rows), params) {
+		chunk := rows[c[0]:c[1]]
+		if err := db.Create(&chunk).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) SetProfileTheme(ctx context.Context, filter map[string]any, theme string) (int, error) {
+	res := d.db.WithContext(ctx).Model(&User{}).
+		Where("profile @> ?", jsonb[map[string]any]{Data: filter}).
+		Update("profile", gorm.Expr("jsonb_set(profile, '{preferences,theme}', to_jsonb(?::text))", theme))
+	return int(res.RowsAffected), res.Error
+}
+
+// FindProfiles selects only the profile column into profileUser, whose
+// jsonb field decodes itself in Scan.
+func (d *Driver) FindProfiles(ctx context.Context, filter map[string]any) ([]bench.Profile, error) {
+	var found []profileUser
+	err := d.db.WithContext(ctx).Select("profile").
+		Where("profile @> ?", jsonb[map[string]any]{Data: filter}).
+		Find(&found).Error
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]bench.Profile, len(found))
+	for i, u := range found {
+		profiles[i] = u.Profile.Data
+	}
+	return profiles, nil
+}
+
+// InsertTypeRows creates the batch from typeSample models, so GORM builds
+// its own multi-row INSERT.
+func (d *Driver) InsertTypeRows(ctx context.Context, rows []bench.TypeRow) error {
+	samples := make([]typeSample, len(rows))
+	for i, r := range rows {
+		samples[i] = typeSample{
+			UID:        bench.FormatUUID(r.UID),
+			Amount:     r.Amount,
+			IDs:        r.IDs,
+			Labels:     r.Labels,
+			Payload:    r.Payload,
+			Addr:       r.Addr.String(),
+			Span:       fmt.Sprintf("%d microseconds", r.Span.Microseconds()),
+			HappenedAt: r.HappenedAt,
+			Note:       r.Note,
+			Quantity:   r.Quantity,
+		}
+	}
+	return d.db.WithContext(ctx).Create(&samples).Error
+}
+
+// ReadTypeRows finds every typeSample in id order and parses the columns
+// held as text.
+func (d *Driver) ReadTypeRows(ctx context.Context) ([]bench.TypeRow, error) {
+	var samples []typeSample
+	if err := d.db.WithContext(ctx).Order("id").Find(&samples).Error; err != nil {
+		return nil, err
+	}
+
+	rows := make([]bench.TypeRow, len(samples))
+	for i, s := range samples {
+		r := bench.TypeRow{
+			Amount:     s.Amount,
+			IDs:        s.IDs,
+			Labels:     s.Labels,
+			Payload:    s.Payload,
+			HappenedAt: s.HappenedAt,
+			Note:       s.Note,
+			Quantity:   s.Quantity,
+		}
+		var err error
+		if r.UID, err = bench.ParseUUID(s.UID); err != nil {
+			return nil, err
+		}
+		if r.Addr, err = netip.ParseAddr(s.Addr); err != nil {
+			return nil, err
+		}
+		if r.Span, err = bench.ParseInterval(s.Span); err != nil {
+			return nil, err
+		}
+		rows[i] = r
+	}
+	return rows, nil
+}
+
+func (d *Driver) WriteBytea(ctx context.Context, data []byte) (int64, error) {
+	b := blob{Data: data}
+	err := d.db.WithContext(ctx).Create(&b).Error
+	return b.ID, err
+}
+
+func (d *Driver) ReadBytea(ctx context.Context, id int64) ([]byte, error) {
+	var b blob
+	err := d.db.WithContext(ctx).First(&b, id).Error
+	return b.Data, err
+}
+
+// LoadNaive finds the posts of every user and the comments of every post
+// one Find at a time, as lazy loading in a loop would.
+func (d *Driver) LoadNaive(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	db := d.db.WithContext(ctx)
+	start := d.queries.Load()
+
+	var found []User
+	if err := db.Order("id").Limit(n).Find(&found).Error; err != nil {
+		return nil, int(d.queries.Load() - start), err
+	}
+	users := make([]bench.UserPosts, len(found))
+	for i, u := range found {
+		var posts []post
+		if err := db.Where("user_id = ?", u.ID).Order("id").Find(&posts).Error; err != nil {
+			return nil, int(d.queries.Load() - start), err
+		}
+		for j := range posts {
+			if err := db.Where("post_id = ?", posts[j].ID).Order("id").Find(&posts[j].Comments).Error; err != nil {
+				return nil, int(d.queries.Load() - start), err
+			}
+		}
+		users[i] = bench.UserPosts{Name: u.Name, Email: u.Email, Posts: toPosts(posts)}
+	}
+	return users, int(d.queries.Load() - start), nil
+}
+
+// LoadPreload finds the users with Preload, which sends one IN query for
+// the posts and one for their comments.
+func (d *Driver) LoadPreload(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	start := d.queries.Load()
+	var authors []author
+	err := d.db.WithContext(ctx).
+		Preload("Posts", orderByID).
+		Preload("Posts.Comments", orderByID).
+		Order("id").Limit(n).Find(&authors).Error
+	queries := int(d.queries.Load() - start)
+	if err != nil {
+		return nil, queries, err
+	}
+
+	users := make([]bench.UserPosts, len(authors))
+	for i, a := range authors {
+		users[i] = bench.UserPosts{Name: a.Name, Email: a.Email, Posts: toPosts(a.Posts)}
+	}
+	return users, queries, nil
+}
+
+// LoadJoined finds the posts joined with their users. GORM's Joins only
+// loads has-one and belongs-to associations, so the query starts from
+// posts, the comments still come from a Preload, and the posts are grouped
+// back by user. A user without posts would be left out, which the
+// Relations phases rule out by requiring at least one post per user.
+func (d *Driver) LoadJoined(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	db := d.db.WithContext(ctx)
+	start := d.queries.Load()
+	var posts []post
+	err := db.Joins("User").Preload("Comments", orderByID).
+		Where("posts.user_id IN (?)", db.Model(&User{}).Select("id").Order("id").Limit(n)).
+		Order("posts.user_id, posts.id").Find(&posts).Error
+	queries := int(d.queries.Load() - start)
+	if err != nil {
+		return nil, queries, err
+	}
+
+	var users []bench.UserPosts
+	for i, p := range posts {
+		if i == 0 || p.UserID != posts[i-1].UserID {
+			users = append(users, bench.UserPosts{Name: p.User.Name, Email: p.User.Email})
+		}
+		u := &users[len(users)-1]
+		u.Posts = append(u.Posts, toPost(p))
+	}
+	return users, queries, nil
+}
+
+// orderByID orders a Preload by id, as the other loaders do.
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func toPosts(posts []post) []bench.Post {
+	out := make([]bench.Post, len(posts))
+	for i, p := range posts {
+		out[i] = toPost(p)
+	}
+	return out
+}
+
+func toPost(p post) bench.Post {
+	comments := make([]bench.Comment, len(p.Comments))
+	for i, c := range p.Comments {
+		comments[i] = bench.Comment{Body: c.Body}
+	}
+	return bench.Post{Title: p.Title, Body: p.Body, Comments: comments}
+}
+
+// Contend renames the given users one Update at a time inside
+// db.Transaction. *gorm.DB is safe for concurrent use.
+func (d *Driver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, id := range ids {
+			if err := tx.Model(&User{}).Where("id = ?", id).Update("name", fmt.Sprintf("Contended_User_%06d", id)).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}, opts)
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	var userCount int64
+	err := d.db.WithContext(ctx).Model(&User{}).Count(&userCount).Error
+	return int(userCount), err
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	db := d.db.WithContext(ctx)
+
+	// Get IDs of users to update
+	userIDs, count, err := d.pluckIDs(db.Model(&User{}).Limit(n))
+	if err != nil {
+		return 0, err
+	}
+
+	// Bulk update using a single statement
+	if err := db.Model(&User{}).Where("id IN ?", userIDs).Update("name", "Updated_User_Bulk").Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	db := d.db.WithContext(ctx)
+
+	// Get IDs of users to delete
+	userIDsToDelete, count, err := d.pluckIDs(db.Model(&User{}).Offset(1000).Limit(n))
+	if err != nil {
+		return 0, err
+	}
+
+	// Bulk delete using a single statement
+	if err := db.Delete(&User{}, userIDsToDelete).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// pluckIDs plucks the ids of the users selected by db into []uint, or into
+// []string when users.id is a uuid, and returns them with their number.
+func (d *Driver) pluckIDs(db *gorm.DB) (any, int, error) {
+	if d.uuidKeys {
+		var ids []string
+		err := db.Pluck("id", &ids).Error
+		return ids, len(ids), err
+	}
+	var ids []uint
+	err := db.Pluck("id", &ids).Error
+	return ids, len(ids), err
+}
@@ -0,0 +1,678 @@
+// Package pgxdriver implements the benchmark phases with the native pgx
+// driver, over a single connection (pgx) and over a pgxpool (pgxpool).
+//
+// Both read the query exec mode and statement cache capacity from the
+// DatabaseConfig. A mode other than pgx's default cache_statement is
+// appended to the driver name, e.g. "PGX[simple_protocol]".
+package pgxdriver
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func init() {
+	bench.Register("pgx", New)
+	bench.Register("pgxpool", NewPool)
+}
+
+// querier is the part of the pgx API shared by *pgx.Conn, *pgxpool.Pool
+// and pgx.Tx.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+	CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error)
+}
+
+// beginner starts transactions; *pgx.Conn and *pgxpool.Pool implement it,
+// pgx.Tx does not.
+type beginner interface {
+	BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error)
+}
+
+// phases implements the benchmark phases on top of a querier, so that the
+// single connection and the pool run exactly the same statements.
+type phases struct {
+	db       querier
+	execMode pgx.QueryExecMode
+	uuidKeys bool // users.idがuuid（文字列で受け渡す）
+}
+
+// InTx runs fn with every phase statement sent on a pgx.Tx.
+func (d *phases) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	db := d.db
+	b, ok := db.(beginner)
+	if !ok {
+		return errors.New("pgxdriver: nested transaction")
+	}
+	return pgx.BeginTxFunc(ctx, b, bench.PgxTxOptions(opts), func(tx pgx.Tx) error {
+		d.db = tx
+		defer func() { d.db = db }()
+		return fn(ctx)
+	})
+}
+
+// configure applies the pgx settings of cfg to a connection config.
+func (d *phases) configure(connConfig *pgx.ConnConfig, cfg *config.DatabaseConfig) error {
+	mode, err := parseExecMode(cfg.PgxQueryExecMode)
+	if err != nil {
+		return err
+	}
+	connConfig.DefaultQueryExecMode = mode
+	connConfig.StatementCacheCapacity = cfg.PgxStatementCacheCapacity
+	d.execMode = mode
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	return nil
+}
+
+// registerTypes makes pgx encode the JSONB phase values as jsonb even in
+// the exec modes that do not ask the server for parameter types, with the
+// timed encoding/json functions of the bench package.
+func registerTypes(m *pgtype.Map) {
+	m.RegisterType(&pgtype.Type{Name: "jsonb", OID: pgtype.JSONBOID, Codec: &pgtype.JSONBCodec{
+		Marshal:   bench.MarshalJSON,
+		Unmarshal: bench.UnmarshalJSON,
+	}})
+	m.RegisterDefaultPgType(bench.Profile{}, "jsonb")
+	m.RegisterDefaultPgType(map[string]any{}, "jsonb")
+}
+
+// label appends the exec mode to name unless it is pgx's default.
+func (d *phases) label(name string) string {
+	if d.execMode == pgx.QueryExecModeCacheStatement {
+		return name
+	}
+	return fmt.Sprintf("%s[%s]", name, d.execMode)
+}
+
+// parseExecMode maps one of config.QueryExecModes to its pgx value. An
+// empty name selects pgx's default.
+func parseExecMode(name string) (pgx.QueryExecMode, error) {
+	modes := []pgx.QueryExecMode{
+		pgx.QueryExecModeCacheStatement,
+		pgx.QueryExecModeCacheDescribe,
+		pgx.QueryExecModeDescribeExec,
+		pgx.QueryExecModeExec,
+		pgx.QueryExecModeSimpleProtocol,
+	}
+	if name == "" {
+		return pgx.QueryExecModeCacheStatement, nil
+	}
+	for _, m := range modes {
+		if m.String() == name {
+			return m, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown query exec mode %q (available: %v)", name, config.QueryExecModes)
+}
+
+// Driver runs the phases through a single *pgx.Conn.
+type Driver struct {
+	phases
+	conn *pgx.Conn
+}
+
+// New returns an unopened pgx driver.
+func New() bench.Driver {
+	return &Driver{}
+}
+
+func (d *Driver) Name() string { return d.label("PGX") }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return err
+	}
+	if err := d.configure(connConfig, cfg); err != nil {
+		return err
+	}
+	conn, err := pgx.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return err
+	}
+	registerTypes(conn.TypeMap())
+	d.conn = conn
+	d.db = conn
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.conn.Close(context.Background())
+}
+
+func (d *phases) Reset(ctx context.Context) error {
+	_, err := d.db.Exec(ctx, "TRUNCATE TABLE users RESTART IDENTITY")
+	return err
+}
+
+func (d *phases) Insert(ctx context.Context, users []bench.User) error {
+	// Prepare batch insert
+	batch := &pgx.Batch{}
+	for _, u := range users {
+		batch.Queue(insertSQL(u), insertArgs(u)...)
+	}
+
+	// Execute batch
+	batchResults := d.db.SendBatch(ctx, batch)
+	for k := range users {
+		if _, err := batchResults.Exec(); err != nil {
+			batchResults.Close()
+			return fmt.Errorf("batch insert %d: %w", k, err)
+		}
+	}
+	return batchResults.Close()
+}
+
+func (d *phases) Count(ctx context.Context) (int, error) {
+	var userCount int
+	err := d.db.QueryRow(ctx, "SELECT COUNT(*) FROM users").Scan(&userCount)
+	return userCount, err
+}
+
+func (d *phases) Update(ctx context.Context, n int) (int, error) {
+	if d.uuidKeys {
+		return update[string](ctx, d.db, n)
+	}
+	return update[int](ctx, d.db, n)
+}
+
+func (d *phases) Delete(ctx context.Context, n int) (int, error) {
+	if d.uuidKeys {
+		return deleteUsers[string](ctx, d.db, n)
+	}
+	return deleteUsers[int](ctx, d.db, n)
+}
+
+// update renames up to n users, scanning their ids as T: int for the
+// integer keys, string for the uuid ones.
+func update[T any](ctx context.Context, db querier, n int) (int, error) {
+	// Get users to update
+	userIDs, err := selectIDs[T](ctx, db, "SELECT id FROM users LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	// Batch update users
+	batch := &pgx.Batch{}
+	for _, userID := range userIDs {
+		newName := fmt.Sprintf("Updated_User_%06v", userID)
+		batch.Queue("UPDATE users SET name = $1 WHERE id = $2", newName, userID)
+	}
+
+	batchResults := db.SendBatch(ctx, batch)
+	for range userIDs {
+		if _, err := batchResults.Exec(); err != nil {
+			log.Printf("Failed to execute batch update: %v", err)
+		}
+	}
+	return len(userIDs), batchResults.Close()
+}
+
+// deleteUsers removes up to n users, scanning their ids as T like update.
+func deleteUsers[T any](ctx context.Context, db querier, n int) (int, error) {
+	// Get users to delete
+	deleteIDs, err := selectIDs[T](ctx, db, "SELECT id FROM users OFFSET 1000 LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	// Batch delete users
+	batch := &pgx.Batch{}
+	for _, userID := range deleteIDs {
+		batch.Queue("DELETE FROM users WHERE id = $1", userID)
+	}
+
+	batchResults := db.SendBatch(ctx, batch)
+	for range deleteIDs {
+		if _, err := batchResults.Exec(); err != nil {
+			log.Printf("Failed to execute batch delete: %v", err)
+		}
+	}
+	return len(deleteIDs), batchResults.Close()
+}
+
+// Upsert copies the batch into a temporary table and merges it into users
+// with INSERT ... ON CONFLICT. The temporary table is dropped at the end of
+// every batch: under -tx-scope=phase the transaction is only a savepoint,
+// so ON COMMIT DROP would leave the table to the next batch, which creates
+// it again. Running in a transaction also keeps a pool on a single
+// connection.
+func (d *phases) Upsert(ctx context.Context, users []bench.User) (inserted, updated int, err error) {
+	// クライアントで生成したidがあれば一緒にコピーする
+	defs, columns := "", []string{"name", "email", "created_at"}
+	if len(users) > 0 && users[0].ID != "" {
+		defs, columns = "id UUID, ", append([]string{"id"}, columns...)
+	}
+
+	err = pgx.BeginFunc(ctx, d.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "CREATE TEMP TABLE users_upsert ("+defs+"name VARCHAR(100), email VARCHAR(100), created_at TIMESTAMPTZ) ON COMMIT DROP"); err != nil {
+			return err
+		}
+
+		rows := make([][]any, 0, len(users))
+		for _, u := range users {
+			if u.ID == "" {
+				rows = append(rows, []any{u.Name, u.Email, u.CreatedAt})
+				continue
+			}
+			// COPYはバイナリ形式なので文字列のままでは送れない
+			var id pgtype.UUID
+			if err := id.Scan(u.ID); err != nil {
+				return err
+			}
+			rows = append(rows, []any{id, u.Name, u.Email, u.CreatedAt})
+		}
+		if _, err := tx.CopyFrom(ctx, pgx.Identifier{"users_upsert"}, columns, pgx.CopyFromRows(rows)); err != nil {
+			return err
+		}
+
+		list := strings.Join(columns, ", ")
+		results, err := tx.Query(ctx, `INSERT INTO users (`+list+`)
+			SELECT `+list+` FROM users_upsert
+			ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name
+			RETURNING (xmax = 0)`)
+		if err != nil {
+			return err
+		}
+		flags, err := pgx.CollectRows(results, pgx.RowTo[bool])
+		if err != nil {
+			return err
+		}
+		for _, wasInserted := range flags {
+			if wasInserted {
+				inserted++
+			} else {
+				updated++
+			}
+		}
+		_, err = tx.Exec(ctx, "DROP TABLE users_upsert")
+		return err
+	})
+	return inserted, updated, err
+}
+
+// InsertNested inserts every user in a pgx.Tx started with tx.Begin, which
+// pgx implements as a SAVEPOINT, and rolls back the flagged ones.
+func (d *phases) InsertNested(ctx context.Context, users []bench.User, rollback []bool) error {
+	return pgx.BeginFunc(ctx, d.db, func(tx pgx.Tx) error {
+		for i, u := range users {
+			nested, err := tx.Begin(ctx)
+			if err != nil {
+				return err
+			}
+			if _, err := nested.Exec(ctx, insertSQL(u), insertArgs(u)...); err != nil {
+				nested.Rollback(ctx)
+				return err
+			}
+			if rollback[i] {
+				err = nested.Rollback(ctx)
+			} else {
+				err = nested.Commit(ctx)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ExecSchema runs the DDL statements one Exec at a time.
+func (d *phases) ExecSchema(ctx context.Context, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := d.db.Exec(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertRows copies the batch with CopyFrom, which takes the rows as they
+// are generated.
+func (d *phases) InsertRows(ctx context.Context, s *bench.Schema, rows [][]any) error {
+	_, err := d.db.CopyFrom(ctx, pgx.Identifier{s.Table}, s.ColumnNames(), pgx.CopyFromRows(rows))
+	return err
+}
+
+// InsertProfiles queues one INSERT per user, passing the bench.Profile
+// itself, which pgx encodes with bench.MarshalJSON.
+func (d *phases) InsertProfiles(ctx context.Context, users []bench.User, profiles []bench.Profile) error {
+	batch := &pgx.Batch{}
+	for i, u := range users {
+		if u.ID != "" {
+			batch.Queue("INSERT INTO users (id, name, email, created_at, profile) VALUES ($1, $2, $3, $4, $5)", u.ID, u.Name, u.Email, u.CreatedAt, profiles[i])
+		} else {
+			batch.Queue("INSERT INTO users (name, email, created_at, profile) VALUES ($1, $2, $3, $4)", u.Name, u.Email, u.CreatedAt, profiles[i])
+		}
+	}
+	return d.db.SendBatch(ctx, batch).Close()
+}
+
+func (d *phases) SetProfileTheme(ctx context.Context, filter map[string]any, theme string) (int, error) {
+	tag, err := d.db.Exec(ctx, "UPDATE users SET profile = jsonb_set(profile, '{preferences,theme}', to_jsonb($1::text)) WHERE profile @> $2", theme, filter)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// FindProfiles scans every profile straight into a bench.Profile.
+func (d *phases) FindProfiles(ctx context.Context, filter map[string]any) ([]bench.Profile, error) {
+	rows, err := d.db.Query(ctx, "SELECT profile FROM users WHERE profile @> $1", filter)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[bench.Profile])
+}
+
+// InsertTypeRows passes the TypeRow fields as they are, except the numeric
+// string, which goes through pgtype.Numeric so that pgx can send it in
+// binary.
+func (d *phases) InsertTypeRows(ctx context.Context, rows []bench.TypeRow) error {
+	args := make([]any, 0, len(rows)*10)
+	for _, r := range rows {
+		var amount pgtype.Numeric
+		if err := amount.Scan(r.Amount); err != nil {
+			return err
+		}
+		args = append(args, r.UID, amount, r.IDs, r.Labels, r.Payload, r.Addr, r.Span, r.HappenedAt, r.Note, r.Quantity)
+	}
+	_, err := d.db.Exec(ctx, bench.TypeRowsInsertSQL(len(rows)), args...)
+	return err
+}
+
+func (d *phases) ReadTypeRows(ctx context.Context) ([]bench.TypeRow, error) {
+	rows, err := d.db.Query(ctx, bench.TypeRowsSelectSQL)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (bench.TypeRow, error) {
+		var r bench.TypeRow
+		var amount pgtype.Numeric
+		err := row.Scan(&r.UID, &amount, &r.IDs, &r.Labels, &r.Payload, &r.Addr, &r.Span, &r.HappenedAt, &r.Note, &r.Quantity)
+		if err != nil {
+			return r, err
+		}
+		v, err := amount.Value()
+		r.Amount, _ = v.(string)
+		return r, err
+	})
+}
+
+func (d *phases) WriteBytea(ctx context.Context, data []byte) (int64, error) {
+	var id int64
+	err := d.db.QueryRow(ctx, "INSERT INTO blobs (data) VALUES ($1) RETURNING id", data).Scan(&id)
+	return id, err
+}
+
+func (d *phases) ReadBytea(ctx context.Context, id int64) ([]byte, error) {
+	var data []byte
+	err := d.db.QueryRow(ctx, "SELECT data FROM blobs WHERE id = $1", id).Scan(&data)
+	return data, err
+}
+
+// WriteLargeObject creates and writes the object through tx.LargeObjects,
+// which only works inside a transaction.
+func (d *phases) WriteLargeObject(ctx context.Context, data []byte) (uint32, error) {
+	var oid uint32
+	err := pgx.BeginFunc(ctx, d.db, func(tx pgx.Tx) error {
+		los := tx.LargeObjects()
+		var err error
+		if oid, err = los.Create(ctx, 0); err != nil {
+			return err
+		}
+		obj, err := los.Open(ctx, oid, pgx.LargeObjectModeWrite)
+		if err != nil {
+			return err
+		}
+		if _, err := obj.Write(data); err != nil {
+			return err
+		}
+		return obj.Close()
+	})
+	return oid, err
+}
+
+// ReadLargeObject seeks to the end for the size and reads the object into
+// a buffer of exactly that size.
+func (d *phases) ReadLargeObject(ctx context.Context, oid uint32) ([]byte, error) {
+	var data []byte
+	err := pgx.BeginFunc(ctx, d.db, func(tx pgx.Tx) error {
+		los := tx.LargeObjects()
+		obj, err := los.Open(ctx, oid, pgx.LargeObjectModeRead)
+		if err != nil {
+			return err
+		}
+		size, err := obj.Seek(0, io.SeekEnd)
+		if err != nil {
+			return err
+		}
+		if _, err := obj.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		data = make([]byte, size)
+		if _, err := io.ReadFull(obj, data); err != nil {
+			return err
+		}
+		return obj.Close()
+	})
+	return data, err
+}
+
+func (d *phases) UnlinkLargeObject(ctx context.Context, oid uint32) error {
+	return pgx.BeginFunc(ctx, d.db, func(tx pgx.Tx) error {
+		los := tx.LargeObjects()
+		return los.Unlink(ctx, oid)
+	})
+}
+
+// LoadNaive and LoadPreload scan users.id as an int64, or as a pgtype.UUID
+// when users.id is a uuid, and pass it back as it was scanned.
+func (d *phases) LoadNaive(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	if d.uuidKeys {
+		return loadNaive[pgtype.UUID](ctx, d.db, n)
+	}
+	return loadNaive[int64](ctx, d.db, n)
+}
+
+func (d *phases) LoadPreload(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	if d.uuidKeys {
+		return loadPreload[pgtype.UUID](ctx, d.db, n)
+	}
+	return loadPreload[int64](ctx, d.db, n)
+}
+
+// LoadJoined decodes the json_agg array of every user straight into its
+// []bench.Post.
+func (d *phases) LoadJoined(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	rows, err := d.db.Query(ctx, bench.UserPostsJSONSQL, n)
+	if err != nil {
+		return nil, 1, err
+	}
+	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (bench.UserPosts, error) {
+		var u bench.UserPosts
+		err := row.Scan(&u.Name, &u.Email, &u.Posts)
+		return u, err
+	})
+	return users, 1, err
+}
+
+// selectUsers returns the ids and the rows of the first n users.
+func selectUsers[K any](ctx context.Context, db querier, n int) ([]K, []bench.UserPosts, error) {
+	rows, err := db.Query(ctx, bench.RelationUsersSQL, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ids []K
+	var users []bench.UserPosts
+	for rows.Next() {
+		var id K
+		var u bench.UserPosts
+		if err := rows.Scan(&id, &u.Name, &u.Email); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		users = append(users, u)
+	}
+	return ids, users, rows.Err()
+}
+
+// loadNaive sends one posts query per user and one comments query per
+// post, like lazy loading in a loop.
+func loadNaive[K any](ctx context.Context, db querier, n int) ([]bench.UserPosts, int, error) {
+	ids, users, err := selectUsers[K](ctx, db, n)
+	queries := 1
+	if err != nil {
+		return nil, queries, err
+	}
+
+	for i, id := range ids {
+		rows, err := db.Query(ctx, bench.PostsOfUserSQL, id)
+		queries++
+		if err != nil {
+			return nil, queries, err
+		}
+		var postIDs []int64
+		for rows.Next() {
+			var postID int64
+			var p bench.Post
+			if err := rows.Scan(&postID, &p.Title, &p.Body); err != nil {
+				rows.Close()
+				return nil, queries, err
+			}
+			postIDs = append(postIDs, postID)
+			users[i].Posts = append(users[i].Posts, p)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, queries, err
+		}
+
+		for j, postID := range postIDs {
+			rows, err := db.Query(ctx, bench.CommentsOfPostSQL, postID)
+			queries++
+			if err != nil {
+				return nil, queries, err
+			}
+			comments, err := pgx.CollectRows(rows, pgx.RowToStructByPos[bench.Comment])
+			if err != nil {
+				return nil, queries, err
+			}
+			users[i].Posts[j].Comments = comments
+		}
+	}
+	return users, queries, nil
+}
+
+// loadPreload sends one posts query for all the users and one comments
+// query for all their posts, passing the ids as arrays, and puts every row
+// under its parent through a map from id to position.
+func loadPreload[K comparable](ctx context.Context, db querier, n int) ([]bench.UserPosts, int, error) {
+	ids, users, err := selectUsers[K](ctx, db, n)
+	queries := 1
+	if err != nil {
+		return nil, queries, err
+	}
+	userIndex := make(map[K]int, len(ids))
+	for i, id := range ids {
+		userIndex[id] = i
+	}
+
+	// 投稿のIDから、その投稿の位置（ユーザーと投稿のインデックス）を引く
+	type postRef struct{ user, post int }
+	postIndex := map[int64]postRef{}
+	var postIDs []int64
+
+	rows, err := db.Query(ctx, bench.PostsOfUsersSQL, ids)
+	queries++
+	if err != nil {
+		return nil, queries, err
+	}
+	for rows.Next() {
+		var postID int64
+		var userID K
+		var p bench.Post
+		if err := rows.Scan(&postID, &userID, &p.Title, &p.Body); err != nil {
+			rows.Close()
+			return nil, queries, err
+		}
+		i := userIndex[userID]
+		postIndex[postID] = postRef{i, len(users[i].Posts)}
+		postIDs = append(postIDs, postID)
+		users[i].Posts = append(users[i].Posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queries, err
+	}
+
+	rows, err = db.Query(ctx, bench.CommentsOfPostsSQL, postIDs)
+	queries++
+	if err != nil {
+		return nil, queries, err
+	}
+	for rows.Next() {
+		var postID int64
+		var c bench.Comment
+		if err := rows.Scan(&postID, &c.Body); err != nil {
+			rows.Close()
+			return nil, queries, err
+		}
+		ref := postIndex[postID]
+		post := &users[ref.user].Posts[ref.post]
+		post.Comments = append(post.Comments, c)
+	}
+	return users, queries, rows.Err()
+}
+
+// insertSQL and insertArgs insert a single user, with its id when the key
+// strategy makes the ids on the client.
+func insertSQL(u bench.User) string {
+	if u.ID != "" {
+		return "INSERT INTO users (id, name, email, created_at) VALUES ($1, $2, $3, $4)"
+	}
+	return "INSERT INTO users (name, email, created_at) VALUES ($1, $2, $3)"
+}
+
+func insertArgs(u bench.User) []any {
+	if u.ID != "" {
+		return []any{u.ID, u.Name, u.Email, u.CreatedAt}
+	}
+	return []any{u.Name, u.Email, u.CreatedAt}
+}
+
+// selectIDs runs an id query with a single LIMIT argument.
+func selectIDs[T any](ctx context.Context, db querier, query string, limit int) ([]T, error) {
+	rows, err := db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []T
+	for rows.Next() {
+		var id T
+		if err := rows.Scan(&id); err != nil {
+			log.Printf("Failed to scan user ID: %v", err)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
@@ -0,0 +1,86 @@
+package pgxdriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolDriver runs the same phases as Driver through a *pgxpool.Pool, so
+// that acquire/release overhead and health checks show up in the timings.
+type PoolDriver struct {
+	phases
+	pool *pgxpool.Pool
+}
+
+// NewPool returns an unopened pgxpool driver.
+func NewPool() bench.Driver {
+	return &PoolDriver{}
+}
+
+func (d *PoolDriver) Name() string { return d.label("PGXPOOL") }
+
+func (d *PoolDriver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	poolConfig, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return err
+	}
+	poolConfig.MaxConns = int32(cfg.MaxConns)
+	poolConfig.MinConns = int32(cfg.MinConns)
+	poolConfig.HealthCheckPeriod = cfg.HealthCheckPeriod
+	if err := d.configure(poolConfig.ConnConfig, cfg); err != nil {
+		return err
+	}
+	poolConfig.AfterConnect = func(ctx context.Context, conn *pgx.Conn) error {
+		registerTypes(conn.TypeMap())
+		return nil
+	}
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return err
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return err
+	}
+	d.pool = pool
+	d.db = pool
+	return nil
+}
+
+func (d *PoolDriver) Close() error {
+	d.pool.Close()
+	return nil
+}
+
+// Contend renames the given users one UPDATE at a time in a transaction
+// on a pooled connection. Only the pool implements it: a single *pgx.Conn
+// cannot serve concurrent clients.
+func (d *PoolDriver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	return pgx.BeginTxFunc(ctx, d.pool, bench.PgxTxOptions(opts), func(tx pgx.Tx) error {
+		for _, id := range ids {
+			if _, err := tx.Exec(ctx, "UPDATE users SET name = $1 WHERE id = $2", fmt.Sprintf("Contended_User_%06d", id), id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Stats reports the cumulative pool counters; Run turns them into
+// per-phase deltas.
+func (d *PoolDriver) Stats() map[string]float64 {
+	stat := d.pool.Stat()
+	return map[string]float64{
+		"AcquireCount":        float64(stat.AcquireCount()),
+		"AcquireDuration(ms)": float64(stat.AcquireDuration().Microseconds()) / 1000,
+		"EmptyAcquireCount":   float64(stat.EmptyAcquireCount()),
+	}
+}
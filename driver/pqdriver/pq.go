@@ -0,0 +1,683 @@
+// Package pqdriver implements the benchmark phases with database/sql, over
+// the lib/pq driver (pq) and over pgx's database/sql driver (pgxstdlib).
+// Both run the same SQL and code path, so comparing them separates the
+// wire-driver cost from the database/sql API cost.
+package pqdriver
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+)
+
+func init() {
+	bench.Register("pq", New)
+	bench.Register("pgxstdlib", NewStdlib)
+}
+
+// execer is the part of the database/sql API shared by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Driver runs the phases through a *sql.DB, or the *sql.Tx of InTx.
+type Driver struct {
+	db         *sql.DB
+	q          execer
+	sqlDriver  string // database/sqlに登録されたドライバー名
+	driverName string
+	uuidKeys   bool // users.idがuuid（文字列で受け渡す）
+}
+
+// New returns an unopened lib/pq driver.
+func New() bench.Driver {
+	return &Driver{sqlDriver: "postgres", driverName: "PQ"}
+}
+
+// NewStdlib returns an unopened driver that uses pgx through database/sql.
+func NewStdlib() bench.Driver {
+	return &Driver{sqlDriver: "pgx", driverName: "PGX-STDLIB"}
+}
+
+func (d *Driver) Name() string { return d.driverName }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	db, err := sql.Open(d.sqlDriver, dsn)
+	if err != nil {
+		return err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	d.db = db
+	d.q = db
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.db.Close()
+}
+
+// InTx runs fn with every phase statement sent on a *sql.Tx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	d.q = tx
+	defer func() { d.q = d.db }()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *Driver) Reset(ctx context.Context) error {
+	_, err := d.q.ExecContext(ctx, "TRUNCATE TABLE users RESTART IDENTITY")
+	return err
+}
+
+// Insert uses a multi-row INSERT for the whole batch, split by
+// bench.ParamChunks when the batch has more bind parameters than one
+// statement accepts.
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)) {
+		values, args := userValues(users[c[0]:c[1]], nil)
+		query := fmt.Sprintf("INSERT INTO users (%s) VALUES %s", userColumns(users), values)
+		if _, err := d.q.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Upsert uses a multi-row INSERT ... ON CONFLICT for the whole batch, split
+// like Insert.
+func (d *Driver) Upsert(ctx context.Context, users []bench.User) (inserted, updated int, err error) {
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)) {
+		i, u, err := d.upsert(ctx, users[c[0]:c[1]])
+		if err != nil {
+			return 0, 0, err
+		}
+		inserted += i
+		updated += u
+	}
+	return inserted, updated, nil
+}
+
+func (d *Driver) upsert(ctx context.Context, users []bench.User) (inserted, updated int, err error) {
+	values, args := userValues(users, nil)
+	query := fmt.Sprintf("INSERT INTO users (%s) VALUES %s ON CONFLICT (email) DO UPDATE SET name = EXCLUDED.name RETURNING (xmax = 0)", userColumns(users), values)
+	rows, err := d.q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var wasInserted bool
+		if err := rows.Scan(&wasInserted); err != nil {
+			return 0, 0, err
+		}
+		if wasInserted {
+			inserted++
+		} else {
+			updated++
+		}
+	}
+	return inserted, updated, rows.Err()
+}
+
+// InsertNested wraps every insert in SAVEPOINT ... RELEASE SAVEPOINT, or
+// ROLLBACK TO SAVEPOINT, as database/sql has no nested transaction API.
+func (d *Driver) InsertNested(ctx context.Context, users []bench.User, rollback []bool) error {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = func() error {
+		for i, u := range users {
+			if _, err := tx.ExecContext(ctx, "SAVEPOINT sp"); err != nil {
+				return err
+			}
+			row, values := userRow(u, 1)
+			if _, err := tx.ExecContext(ctx, "INSERT INTO users ("+userColumns(users)+") VALUES "+row, values...); err != nil {
+				return err
+			}
+			end := "RELEASE SAVEPOINT sp"
+			if rollback[i] {
+				end = "ROLLBACK TO SAVEPOINT sp"
+			}
+			if _, err := tx.ExecContext(ctx, end); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// ExecSchema runs the DDL statements one ExecContext at a time.
+func (d *Driver) ExecSchema(ctx context.Context, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := d.q.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertRows uses a multi-row INSERT for the whole batch.
+func (d *Driver) InsertRows(ctx context.Context, s *bench.Schema, rows [][]any) error {
+	args := make([]any, 0, len(rows)*len(s.Columns))
+	for _, row := range rows {
+		args = append(args, row...)
+	}
+	_, err := d.q.ExecContext(ctx, s.InsertSQL(len(rows)), args...)
+	return err
+}
+
+// InsertProfiles uses a multi-row INSERT for the whole batch, split like
+// Insert, with every profile marshalled to []byte by bench.MarshalJSON.
+func (d *Driver) InsertProfiles(ctx context.Context, users []bench.User, profiles []bench.Profile) error {
+	extra := make([]any, len(users))
+	for i := range users {
+		profile, err := bench.MarshalJSON(profiles[i])
+		if err != nil {
+			return err
+		}
+		extra[i] = profile
+	}
+
+	// profile列の分だけ1行あたりのパラメータが増える
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)+1) {
+		values, args := userValues(users[c[0]:c[1]], extra[c[0]:c[1]])
+		query := fmt.Sprintf("INSERT INTO users (%s, profile) VALUES %s", userColumns(users), values)
+		if _, err := d.q.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Driver) SetProfileTheme(ctx context.Context, filter map[string]any, theme string) (int, error) {
+	f, err := bench.MarshalJSON(filter)
+	if err != nil {
+		return 0, err
+	}
+	res, err := d.q.ExecContext(ctx, "UPDATE users SET profile = jsonb_set(profile, '{preferences,theme}', to_jsonb($1::text)) WHERE profile @> $2", theme, f)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// FindProfiles scans every profile into []byte and unmarshals it.
+func (d *Driver) FindProfiles(ctx context.Context, filter map[string]any) ([]bench.Profile, error) {
+	f, err := bench.MarshalJSON(filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.q.QueryContext(ctx, "SELECT profile FROM users WHERE profile @> $1", f)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []bench.Profile
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var p bench.Profile
+		if err := bench.UnmarshalJSON(data, &p); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+// InsertTypeRows sends every value in a form database/sql accepts: arrays
+// through pq.Array, uuid, inet and interval as text and the nullable
+// columns as sql.Null* values.
+func (d *Driver) InsertTypeRows(ctx context.Context, rows []bench.TypeRow) error {
+	args := make([]interface{}, 0, len(rows)*10)
+	for _, r := range rows {
+		note := sql.NullString{}
+		if r.Note != nil {
+			note = sql.NullString{String: *r.Note, Valid: true}
+		}
+		quantity := sql.NullInt64{}
+		if r.Quantity != nil {
+			quantity = sql.NullInt64{Int64: *r.Quantity, Valid: true}
+		}
+		args = append(args, bench.FormatUUID(r.UID), r.Amount, pq.Array(r.IDs), pq.Array(r.Labels), r.Payload,
+			r.Addr.String(), fmt.Sprintf("%d microseconds", r.Span.Microseconds()), r.HappenedAt, note, quantity)
+	}
+	_, err := d.q.ExecContext(ctx, bench.TypeRowsInsertSQL(len(rows)), args...)
+	return err
+}
+
+// ReadTypeRows scans the text forms back and parses them.
+func (d *Driver) ReadTypeRows(ctx context.Context) ([]bench.TypeRow, error) {
+	rows, err := d.q.QueryContext(ctx, bench.TypeRowsSelectSQL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []bench.TypeRow
+	for rows.Next() {
+		var r bench.TypeRow
+		var uid, addr, span string
+		var note sql.NullString
+		var quantity sql.NullInt64
+		err := rows.Scan(&uid, &r.Amount, pq.Array(&r.IDs), pq.Array(&r.Labels), &r.Payload, &addr, &span, &r.HappenedAt, &note, &quantity)
+		if err != nil {
+			return nil, err
+		}
+		if r.UID, err = bench.ParseUUID(uid); err != nil {
+			return nil, err
+		}
+		if r.Addr, err = netip.ParseAddr(addr); err != nil {
+			return nil, err
+		}
+		if r.Span, err = bench.ParseInterval(span); err != nil {
+			return nil, err
+		}
+		if note.Valid {
+			r.Note = &note.String
+		}
+		if quantity.Valid {
+			r.Quantity = &quantity.Int64
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+func (d *Driver) WriteBytea(ctx context.Context, data []byte) (int64, error) {
+	var id int64
+	err := d.q.QueryRowContext(ctx, "INSERT INTO blobs (data) VALUES ($1) RETURNING id", data).Scan(&id)
+	return id, err
+}
+
+func (d *Driver) ReadBytea(ctx context.Context, id int64) ([]byte, error) {
+	var data []byte
+	err := d.q.QueryRowContext(ctx, "SELECT data FROM blobs WHERE id = $1", id).Scan(&data)
+	return data, err
+}
+
+// WriteLargeObject uses the server-side lo_from_bytea, as database/sql has
+// no large-object API.
+func (d *Driver) WriteLargeObject(ctx context.Context, data []byte) (uint32, error) {
+	var oid uint32
+	err := d.q.QueryRowContext(ctx, "SELECT lo_from_bytea(0, $1)", data).Scan(&oid)
+	return oid, err
+}
+
+func (d *Driver) ReadLargeObject(ctx context.Context, oid uint32) ([]byte, error) {
+	var data []byte
+	err := d.q.QueryRowContext(ctx, "SELECT lo_get($1)", oid).Scan(&data)
+	return data, err
+}
+
+func (d *Driver) UnlinkLargeObject(ctx context.Context, oid uint32) error {
+	_, err := d.q.ExecContext(ctx, "SELECT lo_unlink($1)", oid)
+	return err
+}
+
+// Contend renames the given users one UPDATE at a time in a transaction
+// of its own. *sql.DB hands each concurrent call its own connection.
+func (d *Driver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	tx, err := d.db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", fmt.Sprintf("Contended_User_%06d", id), id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	var userCount int
+	err := d.q.QueryRowContext(ctx, "SELECT COUNT(*) FROM users").Scan(&userCount)
+	return userCount, err
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	ids, err := d.selectIDs(ctx, "SELECT id FROM users LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(ids) > 0 {
+		newName := "Updated_User_Bulk_PQ"
+		query := fmt.Sprintf("UPDATE users SET name = $1 WHERE id IN (%s)", buildPlaceholders(len(ids), 2))
+
+		args := make([]interface{}, len(ids)+1)
+		args[0] = newName
+		for i, id := range ids {
+			args[i+1] = id
+		}
+
+		if _, err := d.q.ExecContext(ctx, query, args...); err != nil {
+			return 0, err
+		}
+	}
+	return len(ids), nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	deleteIDs, err := d.selectIDs(ctx, "SELECT id FROM users OFFSET 1000 LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(deleteIDs) > 0 {
+		query := fmt.Sprintf("DELETE FROM users WHERE id IN (%s)", buildPlaceholders(len(deleteIDs), 1))
+
+		args := make([]interface{}, len(deleteIDs))
+		for i, id := range deleteIDs {
+			args[i] = id
+		}
+
+		if _, err := d.q.ExecContext(ctx, query, args...); err != nil {
+			return 0, err
+		}
+	}
+	return len(deleteIDs), nil
+}
+
+// LoadNaive and LoadPreload scan users.id as an int64, or as a string
+// when users.id is a uuid.
+func (d *Driver) LoadNaive(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	if d.uuidKeys {
+		return loadNaive[string](ctx, d.q, n)
+	}
+	return loadNaive[int64](ctx, d.q, n)
+}
+
+func (d *Driver) LoadPreload(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	if d.uuidKeys {
+		return loadPreload[string](ctx, d.q, n)
+	}
+	return loadPreload[int64](ctx, d.q, n)
+}
+
+// LoadJoined scans the json_agg array of every user into []byte and
+// unmarshals it.
+func (d *Driver) LoadJoined(ctx context.Context, n int) ([]bench.UserPosts, int, error) {
+	rows, err := d.q.QueryContext(ctx, bench.UserPostsJSONSQL, n)
+	if err != nil {
+		return nil, 1, err
+	}
+	defer rows.Close()
+
+	var users []bench.UserPosts
+	for rows.Next() {
+		var u bench.UserPosts
+		var posts []byte
+		if err := rows.Scan(&u.Name, &u.Email, &posts); err != nil {
+			return nil, 1, err
+		}
+		if err := json.Unmarshal(posts, &u.Posts); err != nil {
+			return nil, 1, err
+		}
+		users = append(users, u)
+	}
+	return users, 1, rows.Err()
+}
+
+// selectUsers returns the ids and the rows of the first n users.
+func selectUsers[K any](ctx context.Context, q execer, n int) ([]K, []bench.UserPosts, error) {
+	rows, err := q.QueryContext(ctx, bench.RelationUsersSQL, n)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var ids []K
+	var users []bench.UserPosts
+	for rows.Next() {
+		var id K
+		var u bench.UserPosts
+		if err := rows.Scan(&id, &u.Name, &u.Email); err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		users = append(users, u)
+	}
+	return ids, users, rows.Err()
+}
+
+// loadNaive sends one posts query per user and one comments query per
+// post, like lazy loading in a loop.
+func loadNaive[K any](ctx context.Context, q execer, n int) ([]bench.UserPosts, int, error) {
+	ids, users, err := selectUsers[K](ctx, q, n)
+	queries := 1
+	if err != nil {
+		return nil, queries, err
+	}
+
+	for i, id := range ids {
+		postIDs, err := func() ([]int64, error) {
+			rows, err := q.QueryContext(ctx, bench.PostsOfUserSQL, id)
+			queries++
+			if err != nil {
+				return nil, err
+			}
+			defer rows.Close()
+
+			var postIDs []int64
+			for rows.Next() {
+				var postID int64
+				var p bench.Post
+				if err := rows.Scan(&postID, &p.Title, &p.Body); err != nil {
+					return nil, err
+				}
+				postIDs = append(postIDs, postID)
+				users[i].Posts = append(users[i].Posts, p)
+			}
+			return postIDs, rows.Err()
+		}()
+		if err != nil {
+			return nil, queries, err
+		}
+
+		for j, postID := range postIDs {
+			comments, err := func() ([]bench.Comment, error) {
+				rows, err := q.QueryContext(ctx, bench.CommentsOfPostSQL, postID)
+				queries++
+				if err != nil {
+					return nil, err
+				}
+				defer rows.Close()
+
+				var comments []bench.Comment
+				for rows.Next() {
+					var c bench.Comment
+					if err := rows.Scan(&c.Body); err != nil {
+						return nil, err
+					}
+					comments = append(comments, c)
+				}
+				return comments, rows.Err()
+			}()
+			if err != nil {
+				return nil, queries, err
+			}
+			users[i].Posts[j].Comments = comments
+		}
+	}
+	return users, queries, nil
+}
+
+// loadPreload sends one posts query for all the users and one comments
+// query for all their posts, passing the ids with pq.Array, and puts every
+// row under its parent through a map from id to position.
+func loadPreload[K comparable](ctx context.Context, q execer, n int) ([]bench.UserPosts, int, error) {
+	ids, users, err := selectUsers[K](ctx, q, n)
+	queries := 1
+	if err != nil {
+		return nil, queries, err
+	}
+	userIndex := make(map[K]int, len(ids))
+	for i, id := range ids {
+		userIndex[id] = i
+	}
+
+	// 投稿のIDから、その投稿の位置（ユーザーと投稿のインデックス）を引く
+	type postRef struct{ user, post int }
+	postIndex := map[int64]postRef{}
+	var postIDs []int64
+
+	rows, err := q.QueryContext(ctx, bench.PostsOfUsersSQL, pq.Array(ids))
+	queries++
+	if err != nil {
+		return nil, queries, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID int64
+		var userID K
+		var p bench.Post
+		if err := rows.Scan(&postID, &userID, &p.Title, &p.Body); err != nil {
+			return nil, queries, err
+		}
+		i := userIndex[userID]
+		postIndex[postID] = postRef{i, len(users[i].Posts)}
+		postIDs = append(postIDs, postID)
+		users[i].Posts = append(users[i].Posts, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queries, err
+	}
+	rows.Close()
+
+	rows, err = q.QueryContext(ctx, bench.CommentsOfPostsSQL, pq.Array(postIDs))
+	queries++
+	if err != nil {
+		return nil, queries, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var postID int64
+		var c bench.Comment
+		if err := rows.Scan(&postID, &c.Body); err != nil {
+			return nil, queries, err
+		}
+		ref := postIndex[postID]
+		post := &users[ref.user].Posts[ref.post]
+		post.Comments = append(post.Comments, c)
+	}
+	return users, queries, rows.Err()
+}
+
+// selectIDs runs an id query with a single LIMIT argument. The ids are
+// ints, or strings when users.id is a uuid, ready to be passed as
+// arguments.
+func (d *Driver) selectIDs(ctx context.Context, query string, limit int) ([]any, error) {
+	rows, err := d.q.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []any
+	for rows.Next() {
+		if d.uuidKeys {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+			continue
+		}
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// userRow returns the VALUES row of u for a multi-row INSERT, with its
+// placeholders starting at $argIndex, and the arguments of the row: the
+// columns of userColumns followed by extra.
+func userRow(u bench.User, argIndex int, extra ...any) (string, []any) {
+	values := make([]any, 0, 4+len(extra))
+	if u.ID != "" {
+		values = append(values, u.ID)
+	}
+	values = append(values, u.Name, u.Email, u.CreatedAt)
+	values = append(values, extra...)
+	return "(" + buildPlaceholders(len(values), argIndex) + ")", values
+}
+
+// userValues returns the VALUES rows of users for one multi-row INSERT and
+// their arguments, with extra[i], when extra is not nil, as the last column
+// of the i-th row.
+func userValues(users []bench.User, extra []any) (string, []any) {
+	rows := make([]string, 0, len(users))
+	args := make([]any, 0, len(users)*(bench.UserParams(users)+1))
+	for i, u := range users {
+		var row string
+		var values []any
+		if extra != nil {
+			row, values = userRow(u, len(args)+1, extra[i])
+		} else {
+			row, values = userRow(u, len(args)+1)
+		}
+		rows = append(rows, row)
+		args = append(args, values...)
+	}
+	return strings.Join(rows, ","), args
+}
+
+// userColumns returns the users columns set by userRow, which start with
+// id when the key strategy makes the ids on the client.
+func userColumns(users []bench.User) string {
+	if len(users) > 0 && users[0].ID != "" {
+		return "id, name, email, created_at"
+	}
+	return "name, email, created_at"
+}
+
+// buildPlaceholders generates a string of placeholders for SQL IN clauses.
+// Example: buildPlaceholders(3, 1) -> "$1, $2, $3"
+func buildPlaceholders(count, start int) string {
+	placeholders := make([]string, count)
+	for i := 0; i < count; i++ {
+		placeholders[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(placeholders, ",")
+}
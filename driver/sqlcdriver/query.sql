@@ -0,0 +1,29 @@
+-- name: TruncateUsers :exec
+TRUNCATE TABLE users RESTART IDENTITY;
+
+-- name: CopyUsers :copyfrom
+INSERT INTO users (name, email, created_at) VALUES ($1, $2, $3);
+
+-- name: InsertUser :batchexec
+INSERT INTO users (name, email, created_at) VALUES ($1, $2, $3);
+
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: ListUserIDs :many
+SELECT id FROM users LIMIT $1;
+
+-- name: ListUserIDsAfterFirst1000 :many
+SELECT id FROM users OFFSET 1000 LIMIT $1;
+
+-- name: UpdateUserNames :exec
+UPDATE users SET name = @name WHERE id = ANY(@ids::int[]);
+
+-- name: UpdateUserName :batchexec
+UPDATE users SET name = $1 WHERE id = $2;
+
+-- name: DeleteUsers :exec
+DELETE FROM users WHERE id = ANY(@ids::int[]);
+
+-- name: DeleteUser :batchexec
+DELETE FROM users WHERE id = $1;
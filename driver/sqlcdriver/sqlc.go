@@ -0,0 +1,222 @@
+// Package sqlcdriver implements the benchmark phases with a type-safe query
+// layer generated by sqlc from query.sql (package usersdb), on top of a
+// single pgx connection. The queries that pass or return users.id are also
+// generated from uuid_query.sql against the uuid table of uuid_schema.sql
+// (package uuidusersdb), for the uuid key strategies.
+//
+// Two variants are registered:
+//
+//	sqlc        CopyFrom for inserts, ANY($1::int[]) for the bulk update and delete
+//	sqlc-batch  :batchexec queries, one statement per row in a pgx.Batch
+//
+// Regenerate usersdb and uuidusersdb after editing the SQL files or
+// init/init.sql with go generate ./driver/sqlcdriver.
+package sqlcdriver
+
+//go:generate go run github.com/sqlc-dev/sqlc/cmd/sqlc@v1.27.0 generate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+	"go-postgresql/driver/sqlcdriver/usersdb"
+	"go-postgresql/driver/sqlcdriver/uuidusersdb"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func init() {
+	bench.Register("sqlc", New)
+	bench.Register("sqlc-batch", func() bench.Driver {
+		return &Driver{batch: true, driverName: "SQLC-BATCH"}
+	})
+}
+
+// Driver runs the phases through the generated usersdb.Queries.
+type Driver struct {
+	conn       *pgx.Conn
+	q          *usersdb.Queries     // InTxの間はトランザクション上のQueries
+	uq         *uuidusersdb.Queries // users.idがuuidの場合のQueries
+	uuidKeys   bool                 // users.idがuuid（uqを使う）
+	batch      bool                 // :batchexecのクエリを使用する
+	driverName string
+}
+
+// New returns an unopened sqlc driver using CopyFrom and ANY.
+func New() bench.Driver {
+	return &Driver{driverName: "SQLC"}
+}
+
+func (d *Driver) Name() string { return d.driverName }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	d.conn = conn
+	d.q = usersdb.New(conn)
+	d.uq = uuidusersdb.New(conn)
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.conn.Close(context.Background())
+}
+
+// InTx runs fn with the queries bound to a pgx.Tx through Queries.WithTx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	q, uq := d.q, d.uq
+	defer func() { d.q, d.uq = q, uq }()
+	return pgx.BeginTxFunc(ctx, d.conn, bench.PgxTxOptions(opts), func(tx pgx.Tx) error {
+		d.q, d.uq = q.WithTx(tx), uq.WithTx(tx)
+		return fn(ctx)
+	})
+}
+
+func (d *Driver) Reset(ctx context.Context) error {
+	return d.q.TruncateUsers(ctx)
+}
+
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	if len(users) > 0 && users[0].ID != "" {
+		return d.insertWithIDs(ctx, users)
+	}
+	if d.batch {
+		params := make([]usersdb.InsertUserParams, 0, len(users))
+		for _, u := range users {
+			params = append(params, usersdb.InsertUserParams{
+				Name:      u.Name,
+				Email:     u.Email,
+				CreatedAt: pgtype.Timestamptz{Time: u.CreatedAt, Valid: true},
+			})
+		}
+		return execBatch(d.q.InsertUser(ctx, params), "insert")
+	}
+
+	params := make([]usersdb.CopyUsersParams, 0, len(users))
+	for _, u := range users {
+		params = append(params, usersdb.CopyUsersParams{
+			Name:      u.Name,
+			Email:     u.Email,
+			CreatedAt: pgtype.Timestamptz{Time: u.CreatedAt, Valid: true},
+		})
+	}
+	_, err := d.q.CopyUsers(ctx, params)
+	return err
+}
+
+// insertWithIDs inserts users with the uuids generated on the client.
+func (d *Driver) insertWithIDs(ctx context.Context, users []bench.User) error {
+	params := make([]uuidusersdb.CopyUsersWithIDParams, 0, len(users))
+	for _, u := range users {
+		var id pgtype.UUID
+		if err := id.Scan(u.ID); err != nil {
+			return err
+		}
+		params = append(params, uuidusersdb.CopyUsersWithIDParams{
+			ID:        id,
+			Name:      u.Name,
+			Email:     u.Email,
+			CreatedAt: pgtype.Timestamptz{Time: u.CreatedAt, Valid: true},
+		})
+	}
+	if d.batch {
+		batchParams := make([]uuidusersdb.InsertUserWithIDParams, 0, len(params))
+		for _, p := range params {
+			batchParams = append(batchParams, uuidusersdb.InsertUserWithIDParams(p))
+		}
+		return execBatch(d.uq.InsertUserWithID(ctx, batchParams), "insert")
+	}
+	_, err := d.uq.CopyUsersWithID(ctx, params)
+	return err
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	userCount, err := d.q.CountUsers(ctx)
+	return int(userCount), err
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	if d.uuidKeys {
+		return d.updateUUIDs(ctx, n)
+	}
+	userIDs, err := d.q.ListUserIDs(ctx, int32(n))
+	if err != nil {
+		return 0, err
+	}
+
+	if d.batch {
+		params := make([]usersdb.UpdateUserNameParams, 0, len(userIDs))
+		for _, id := range userIDs {
+			params = append(params, usersdb.UpdateUserNameParams{Name: fmt.Sprintf("Updated_User_%06d", id), ID: id})
+		}
+		return len(userIDs), execBatch(d.q.UpdateUserName(ctx, params), "update")
+	}
+
+	err = d.q.UpdateUserNames(ctx, usersdb.UpdateUserNamesParams{Name: "Updated_User_Bulk_SQLC", Ids: userIDs})
+	return len(userIDs), err
+}
+
+// updateUUIDs is Update for the uuid key strategies.
+func (d *Driver) updateUUIDs(ctx context.Context, n int) (int, error) {
+	userIDs, err := d.uq.ListUserIDs(ctx, int32(n))
+	if err != nil {
+		return 0, err
+	}
+
+	if d.batch {
+		params := make([]uuidusersdb.UpdateUserNameParams, 0, len(userIDs))
+		for _, id := range userIDs {
+			params = append(params, uuidusersdb.UpdateUserNameParams{Name: "Updated_User_" + bench.FormatUUID(id.Bytes), ID: id})
+		}
+		return len(userIDs), execBatch(d.uq.UpdateUserName(ctx, params), "update")
+	}
+
+	err = d.uq.UpdateUserNames(ctx, uuidusersdb.UpdateUserNamesParams{Name: "Updated_User_Bulk_SQLC", Ids: userIDs})
+	return len(userIDs), err
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	if d.uuidKeys {
+		deleteIDs, err := d.uq.ListUserIDsAfterFirst1000(ctx, int32(n))
+		if err != nil {
+			return 0, err
+		}
+		if d.batch {
+			return len(deleteIDs), execBatch(d.uq.DeleteUser(ctx, deleteIDs), "delete")
+		}
+		return len(deleteIDs), d.uq.DeleteUsers(ctx, deleteIDs)
+	}
+
+	deleteIDs, err := d.q.ListUserIDsAfterFirst1000(ctx, int32(n))
+	if err != nil {
+		return 0, err
+	}
+
+	if d.batch {
+		return len(deleteIDs), execBatch(d.q.DeleteUser(ctx, deleteIDs), "delete")
+	}
+	return len(deleteIDs), d.q.DeleteUsers(ctx, deleteIDs)
+}
+
+// batchResults is the Exec method shared by the generated :batchexec results.
+type batchResults interface {
+	Exec(f func(int, error))
+}
+
+// execBatch runs every queued statement and returns the first error.
+func execBatch(br batchResults, op string) error {
+	var firstErr error
+	br.Exec(func(i int, err error) {
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("batch %s %d: %w", op, i, err)
+		}
+	})
+	return firstErr
+}
@@ -0,0 +1,26 @@
+-- The queries of query.sql that pass or return users.id, for the uuid key
+-- strategies. The others work on either table and stay in query.sql.
+
+-- name: CopyUsersWithID :copyfrom
+INSERT INTO users (id, name, email, created_at) VALUES ($1, $2, $3, $4);
+
+-- name: InsertUserWithID :batchexec
+INSERT INTO users (id, name, email, created_at) VALUES ($1, $2, $3, $4);
+
+-- name: ListUserIDs :many
+SELECT id FROM users LIMIT $1;
+
+-- name: ListUserIDsAfterFirst1000 :many
+SELECT id FROM users OFFSET 1000 LIMIT $1;
+
+-- name: UpdateUserNames :exec
+UPDATE users SET name = @name WHERE id = ANY(@ids::uuid[]);
+
+-- name: UpdateUserName :batchexec
+UPDATE users SET name = $1 WHERE id = $2;
+
+-- name: DeleteUsers :exec
+DELETE FROM users WHERE id = ANY(@ids::uuid[]);
+
+-- name: DeleteUser :batchexec
+DELETE FROM users WHERE id = $1;
@@ -0,0 +1,8 @@
+-- users with the uuid id that bench.PrepareUsers creates for the uuid key
+-- strategies; the other columns are the same as in init/init.sql.
+CREATE TABLE users (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    email VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
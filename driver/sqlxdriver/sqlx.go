@@ -0,0 +1,173 @@
+// Package sqlxdriver implements the benchmark phases with jmoiron/sqlx on
+// top of database/sql and lib/pq: NamedExec with a slice for the multi-row
+// inserts, Select/Get for reads and sqlx.In for the bulk update and delete.
+package sqlxdriver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	bench.Register("sqlx", New)
+}
+
+// User corresponds to the users table, with sqlx column tags.
+// ID is only bound by the inserts of the uuid key strategies whose ids are
+// made on the client.
+type User struct {
+	ID        string    `db:"id"`
+	Name      string    `db:"name"`
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Driver runs the phases through a *sqlx.DB, or the *sqlx.Tx of InTx.
+type Driver struct {
+	db       *sqlx.DB
+	ext      sqlx.ExtContext
+	uuidKeys bool // users.idがuuid（文字列で受け渡す）
+}
+
+// New returns an unopened sqlx driver.
+func New() bench.Driver {
+	return &Driver{}
+}
+
+func (d *Driver) Name() string { return "SQLX" }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	db, err := sqlx.ConnectContext(ctx, "postgres", dsn)
+	if err != nil {
+		return err
+	}
+	d.db = db
+	d.ext = db
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.db.Close()
+}
+
+// InTx runs fn with every phase statement sent on a *sqlx.Tx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := d.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	d.ext = tx
+	defer func() { d.ext = d.db }()
+
+	if err := fn(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+func (d *Driver) Reset(ctx context.Context) error {
+	_, err := d.ext.ExecContext(ctx, "TRUNCATE TABLE users RESTART IDENTITY")
+	return err
+}
+
+// Insert passes the batch to NamedExec, which expands it into a multi-row
+// INSERT, one bench.ParamChunks range at a time so that no statement
+// exceeds the bind parameter limit.
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	batchUsers := make([]User, 0, len(users))
+	for _, u := range users {
+		batchUsers = append(batchUsers, User{ID: u.ID, Name: u.Name, Email: u.Email, CreatedAt: u.CreatedAt})
+	}
+	query := "INSERT INTO users (name, email, created_at) VALUES (:name, :email, :created_at)"
+	if len(users) > 0 && users[0].ID != "" {
+		query = "INSERT INTO users (id, name, email, created_at) VALUES (:id, :name, :email, :created_at)"
+	}
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)) {
+		if _, err := sqlx.NamedExecContext(ctx, d.ext, query, batchUsers[c[0]:c[1]]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Contend renames the given users one UPDATE at a time in a transaction
+// from BeginTxx. *sqlx.DB hands each concurrent call its own connection.
+func (d *Driver) Contend(ctx context.Context, opts *sql.TxOptions, ids []int) error {
+	tx, err := d.db.BeginTxx(ctx, opts)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, "UPDATE users SET name = $1 WHERE id = $2", fmt.Sprintf("Contended_User_%06d", id), id); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	var userCount int
+	err := sqlx.GetContext(ctx, d.ext, &userCount, "SELECT COUNT(*) FROM users")
+	return userCount, err
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	ids, count, err := d.selectIDs(ctx, "SELECT id FROM users LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		query, args, err := sqlx.In("UPDATE users SET name = ? WHERE id IN (?)", "Updated_User_Bulk_SQLX", ids)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := d.ext.ExecContext(ctx, d.ext.Rebind(query), args...); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	deleteIDs, count, err := d.selectIDs(ctx, "SELECT id FROM users OFFSET 1000 LIMIT $1", n)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		query, args, err := sqlx.In("DELETE FROM users WHERE id IN (?)", deleteIDs)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := d.ext.ExecContext(ctx, d.ext.Rebind(query), args...); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// selectIDs runs an id query with a single LIMIT argument into []int, or
+// into []string when users.id is a uuid, and returns the ids for sqlx.In
+// with their number.
+func (d *Driver) selectIDs(ctx context.Context, query string, limit int) (any, int, error) {
+	if d.uuidKeys {
+		var ids []string
+		err := sqlx.SelectContext(ctx, d.ext, &ids, query, limit)
+		return ids, len(ids), err
+	}
+	var ids []int
+	err := sqlx.SelectContext(ctx, d.ext, &ids, query, limit)
+	return ids, len(ids), err
+}
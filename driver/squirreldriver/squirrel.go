@@ -0,0 +1,198 @@
+// Package squirreldriver implements the benchmark phases with SQL built by
+// Masterminds/squirrel and executed on a single pgx connection: a
+// multi-row INSERT per batch and sq.Eq{"id": ids} for the bulk update and
+// delete.
+//
+// The time spent in ToSql is accumulated separately and reported through
+// bench.StatsReporter, so every phase shows how much of it was query
+// building rather than execution.
+package squirreldriver
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go-postgresql/bench"
+	"go-postgresql/config"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func init() {
+	bench.Register("squirrel", New)
+}
+
+// psql builds statements with PostgreSQL's $n placeholders.
+var psql = sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+// querier is the part of the pgx API shared by *pgx.Conn and pgx.Tx.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Driver builds every statement with squirrel and runs it on a *pgx.Conn,
+// or the pgx.Tx of InTx.
+type Driver struct {
+	conn      *pgx.Conn
+	db        querier
+	buildTime time.Duration // ToSqlに費やした累積時間
+	builds    int           // ToSqlの累積呼び出し回数
+	uuidKeys  bool          // users.idがuuid（文字列で受け渡す）
+}
+
+// New returns an unopened squirrel driver.
+func New() bench.Driver {
+	return &Driver{}
+}
+
+func (d *Driver) Name() string { return "SQUIRREL" }
+
+func (d *Driver) Open(ctx context.Context, dsn string, cfg *config.DatabaseConfig) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	d.conn = conn
+	d.db = conn
+	d.uuidKeys = bench.UUIDKeys(cfg)
+	return nil
+}
+
+func (d *Driver) Close() error {
+	return d.conn.Close(context.Background())
+}
+
+// InTx runs fn with every statement sent on a pgx.Tx.
+func (d *Driver) InTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	defer func() { d.db = d.conn }()
+	return pgx.BeginTxFunc(ctx, d.conn, bench.PgxTxOptions(opts), func(tx pgx.Tx) error {
+		d.db = tx
+		return fn(ctx)
+	})
+}
+
+// Stats reports the cumulative query building cost; Run turns it into
+// per-phase deltas.
+func (d *Driver) Stats() map[string]float64 {
+	return map[string]float64{
+		"BuildTime(ms)": float64(d.buildTime.Microseconds()) / 1000,
+		"Builds":        float64(d.builds),
+	}
+}
+
+// build renders b to SQL and accounts the time it took.
+func (d *Driver) build(b sq.Sqlizer) (string, []any, error) {
+	start := time.Now()
+	query, args, err := b.ToSql()
+	d.buildTime += time.Since(start)
+	d.builds++
+	return query, args, err
+}
+
+// exec builds b and executes it.
+func (d *Driver) exec(ctx context.Context, b sq.Sqlizer) error {
+	query, args, err := d.build(b)
+	if err != nil {
+		return err
+	}
+	_, err = d.db.Exec(ctx, query, args...)
+	return err
+}
+
+// Reset runs TRUNCATE directly, as squirrel has no builder for it.
+func (d *Driver) Reset(ctx context.Context) error {
+	_, err := d.db.Exec(ctx, "TRUNCATE TABLE users RESTART IDENTITY")
+	return err
+}
+
+// Insert builds one multi-row INSERT per bench.ParamChunks range, so that a
+// large batch stays within the bind parameters of one statement.
+func (d *Driver) Insert(ctx context.Context, users []bench.User) error {
+	for _, c := range bench.ParamChunks(len(users), bench.UserParams(users)) {
+		if err := d.exec(ctx, insertUsers(users[c[0]:c[1]])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertUsers builds the multi-row INSERT of users, with id when the key
+// strategy makes the ids on the client.
+func insertUsers(users []bench.User) sq.InsertBuilder {
+	if len(users) > 0 && users[0].ID != "" {
+		insert := psql.Insert("users").Columns("id", "name", "email", "created_at")
+		for _, u := range users {
+			insert = insert.Values(u.ID, u.Name, u.Email, u.CreatedAt)
+		}
+		return insert
+	}
+
+	insert := psql.Insert("users").Columns("name", "email", "created_at")
+	for _, u := range users {
+		insert = insert.Values(u.Name, u.Email, u.CreatedAt)
+	}
+	return insert
+}
+
+func (d *Driver) Count(ctx context.Context) (int, error) {
+	query, args, err := d.build(psql.Select("COUNT(*)").From("users"))
+	if err != nil {
+		return 0, err
+	}
+	var userCount int
+	err = d.db.QueryRow(ctx, query, args...).Scan(&userCount)
+	return userCount, err
+}
+
+func (d *Driver) Update(ctx context.Context, n int) (int, error) {
+	userIDs, count, err := d.selectIDs(ctx, psql.Select("id").From("users").Limit(uint64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		update := psql.Update("users").Set("name", "Updated_User_Bulk_SQUIRREL").Where(sq.Eq{"id": userIDs})
+		if err := d.exec(ctx, update); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func (d *Driver) Delete(ctx context.Context, n int) (int, error) {
+	deleteIDs, count, err := d.selectIDs(ctx, psql.Select("id").From("users").Offset(1000).Limit(uint64(n)))
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		if err := d.exec(ctx, psql.Delete("users").Where(sq.Eq{"id": deleteIDs})); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+// selectIDs builds and runs an id query. It returns the ids for sq.Eq,
+// as []int or as []string when users.id is a uuid, with their number.
+func (d *Driver) selectIDs(ctx context.Context, b sq.SelectBuilder) (any, int, error) {
+	query, args, err := d.build(b)
+	if err != nil {
+		return nil, 0, err
+	}
+	rows, err := d.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	if d.uuidKeys {
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		return ids, len(ids), err
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	return ids, len(ids), err
+}